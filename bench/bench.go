@@ -0,0 +1,119 @@
+// Benchmarks for every day and part, usable both from go test -bench and from the -bench mode of the CLI
+package bench
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/io"
+	"aoc2k24/registry"
+	"aoc2k24/selector"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// How often the heap is sampled while looking for the peak. Each sample briefly stops the world, so not too often
+const sampleInterval = time.Millisecond
+
+type Stats struct {
+  Day constants.DayIndex `json:"day"`
+  Part constants.PartIndex `json:"part"`
+  Iterations int `json:"iterations"`
+  NsPerOp int64 `json:"nsPerOp"`
+  AllocsPerOp int64 `json:"allocsPerOp"`
+  BytesPerOp int64 `json:"bytesPerOp"`
+  PeakBytes uint64 `json:"peakBytes"`
+}
+
+// Benchmarks one part. Each iteration parses its own copy of the input, since most days keep state between parts
+// (and some even rewrite the lines), but only the part itself is timed
+func Func(entry registry.Entry, input io.InputInfo, lines []string, part constants.PartIndex) func(*testing.B) {
+  return func(b *testing.B) {
+    b.ReportAllocs()
+    for range b.N {
+      b.StopTimer()
+      solver, err := parse(entry, input, lines)
+      if err != nil { b.Fatal(err) }
+      b.StartTimer()
+      _, err = registry.Solve(solver, part)
+      if err != nil { b.Fatal(err) }
+    }
+  }
+}
+
+// Benchmarks the given parts of every input, or all those its day solves if nil. Parts that fail are left out of the
+// stats and their errors returned instead
+func Run(inputs []io.InputInfo, parts []constants.PartIndex) ([]Stats, []error) {
+  stats := []Stats{}
+  failures := []error{}
+  for _, input := range inputs {
+    day, ver := input.Day, input.Ver
+    entry, lines, err := selector.LoadInput(input)
+    if err != nil {
+      failures = append(failures, err)
+      continue
+    }
+    dayParts, err := selector.PartsToRun(entry, ver, parts)
+    if err != nil {
+      failures = append(failures, err)
+      continue
+    }
+    for _, part := range dayParts {
+      // A first run checks that the part can be solved at all, and is used to measure the peak memory
+      solver, err := parse(entry, input, lines)
+      if err != nil {
+        failures = append(failures, &errs.DayError{Day: day, Ver: ver, Err: err})
+        break
+      }
+      peak := peakHeap(func() { _, err = registry.Solve(solver, part) })
+      if err != nil {
+        failures = append(failures, &errs.DayError{Day: day, Ver: ver, Part: part, Err: err})
+        continue
+      }
+      result := testing.Benchmark(Func(entry, input, lines, part))
+      stats = append(stats, Stats{day, part, result.N, result.NsPerOp(), result.AllocsPerOp(), result.AllocedBytesPerOp(), peak})
+    }
+  }
+  return stats, failures
+}
+
+func parse(entry registry.Entry, input io.InputInfo, lines []string) (registry.Solver, error) {
+  return selector.NewSolver(entry, input, append([]string{}, lines...))
+}
+
+// Samples the heap while run executes and returns how much it grew at its highest point
+func peakHeap(run func()) uint64 {
+  runtime.GC()
+  base := readHeap()
+  peak := base
+  done := make(chan struct{})
+  finished := make(chan struct{})
+  go func() {
+    defer close(finished)
+    ticker := time.NewTicker(sampleInterval)
+    defer ticker.Stop()
+    for {
+      heap := readHeap()
+      if heap > peak { peak = heap }
+      select {
+      case <-done:
+        return
+      case <-ticker.C:
+      }
+    }
+  }()
+  run()
+  close(done)
+  <-finished
+  // Short runs may finish before the first sample, but whatever they allocated is still on the heap
+  heap := readHeap()
+  if heap > peak { peak = heap }
+  return peak - base
+}
+
+func readHeap() uint64 {
+  var stats runtime.MemStats
+  runtime.ReadMemStats(&stats)
+  return stats.HeapAlloc
+}
+
@@ -0,0 +1,36 @@
+package bench_test
+
+import (
+	"aoc2k24/bench"
+	"aoc2k24/constants"
+	_ "aoc2k24/days"
+	"aoc2k24/golden"
+	"aoc2k24/io"
+	"aoc2k24/registry"
+	"aoc2k24/selector"
+	"fmt"
+	"testing"
+)
+
+// One sub-benchmark per day and part over the full puzzle input, e.g. go test -bench 'Days/day06/part2' ./bench
+// Only inputs with golden answers are benchmarked, which leaves out those that don't finish in a reasonable time
+func BenchmarkDays(b *testing.B) {
+  inputs, err := golden.Load()
+  if err != nil { b.Fatal(err) }
+  fullInputs := make(map[constants.DayIndex]io.InputInfo)
+  for _, input := range inputs {
+    if input.Ver == 0 { fullInputs[input.Day] = input }
+  }
+  for _, day := range registry.Days() {
+    input, isKnown := fullInputs[day]
+    if !isKnown { input = io.InputInfo{Day: day, Ver: 0} }
+    entry, lines, err := selector.LoadInput(input)
+    for _, part := range entry.Parts {
+      b.Run(fmt.Sprintf("day%02d/part%d", day, part), func(b *testing.B) {
+        if err != nil { b.Skip(err) }
+        if !isKnown { b.Skip("no golden answers for the full input") }
+        bench.Func(entry, input, lines, part)(b)
+      })
+    }
+  }
+}
@@ -28,4 +28,12 @@ const (
   TwentyTwo
   TwentyThree
   TwentyFour
+  TwentyFive
+)
+
+type PartIndex int
+
+const (
+  Part1 PartIndex = iota + 1
+  Part2
 )
@@ -2,25 +2,37 @@ package d1
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
 	"sort"
 	"strconv"
 	"strings"
 )
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.One, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.One, ver, err))
-  }
-  seq1, seq2 := parseSequences(lines)
-  sort.Sort(sort.IntSlice(seq1))
-  sort.Sort(sort.IntSlice(seq2))
-  sum := getSum(seq1, seq2)
-  similarity := getSimilarity(seq1, seq2)
-  fmt.Printf("The total sum is: %d\n", sum)
-  fmt.Printf("The total similarity is: %d\n", similarity)
+func init() {
+  registry.Register(constants.One, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  seq1 []int
+  seq2 []int
+}
+
+func (s *solver) Parse(lines []string) error {
+  var err error
+  s.seq1, s.seq2, err = parseSequences(lines)
+  if err != nil { return err }
+  sort.Sort(sort.IntSlice(s.seq1))
+  sort.Sort(sort.IntSlice(s.seq2))
+  return nil
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(getSum(s.seq1, s.seq2)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Int(getSimilarity(s.seq1, s.seq2)), nil
 }
 
 func getSum(seq1 []int, seq2 []int) int {
@@ -59,15 +71,22 @@ func getItemCountMap(seq []int) map[int]int {
   return m
 }
 
-func parseSequences(lines []string) ([]int, []int) {
+func parseSequences(lines []string) ([]int, []int, error) {
   seq1 := make([]int, len(lines))
   seq2 := make([]int, len(lines))
-  for _, line := range lines {
+  for i, line := range lines {
     strnums := strings.Split(line, "   ")
-    n1, _ := strconv.Atoi(strnums[0])
-    n2, _ := strconv.Atoi(strnums[1])
+    if len(strnums) != 2 {
+      return nil, nil, errs.Malformed("line %d should hold two numbers: %q", i + 1, line)
+    }
+    n1, err1 := strconv.Atoi(strnums[0])
+    n2, err2 := strconv.Atoi(strnums[1])
+    if err1 != nil || err2 != nil {
+      return nil, nil, errs.Malformed("line %d should hold two numbers: %q", i + 1, line)
+    }
     seq1 = append(seq1, n1)
     seq2 = append(seq2, n2)
   }
-  return seq1, seq2
+  return seq1, seq2, nil
 }
+
@@ -2,156 +2,95 @@ package d10
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
-	"strconv"
+	"aoc2k24/errs"
+	"aoc2k24/grid"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 )
 
-const isDebug = false
+var traceTrails = trace.New(constants.Ten, "trails", trace.Debug, "Every trail explored from each trailhead")
 
-type Path []int
+type Terrain struct {
+  heights *grid.Grid[int]
+  heads []grid.Coord
+}
 
-func (p Path) equals(other Path) bool {
-  if len(p) != len(other) { return false }
-  for i := range len(p) {
-    if p[i] != other[i] { return false }
+func newTerrain(heights *grid.Grid[int]) *Terrain {
+  heads := []grid.Coord{}
+  for c, height := range heights.All() {
+    if height == 0 { heads = append(heads, c) }
   }
-  return true
+  return &Terrain{heights, heads}
 }
 
-func (p Path) toString() string {
-  result := ""
-  for i := range len(p) {
-    result += fmt.Sprintf("%d,", p[i])
-  }
-  return result
+func init() {
+  registry.Register(constants.Ten, func() registry.Solver { return &solver{} })
 }
 
-type Terrain struct {
-  tiles []int
-  width int
-  height int
-  heads map[int]struct{}
+type solver struct {
+  terrain *Terrain
+  isSolved bool
+  scoreSum int
+  uniqueTrailsSum int
 }
 
-func newTerrain(lines *[]string) *Terrain {
-  height := len(*lines)
-  width := len((*lines)[0])
-  tiles := make([]int, height * width)
-  heads := make(map[int]struct{})
-  for y, line := range *lines {
-    for x, char := range line {
-      i := y * width + x
-      value, _ := strconv.Atoi(string(char))
-      tiles[i] = value
-      if value == 0 {
-        heads[i] = struct{}{}
-      }
-    }
-  }
-  return &Terrain{tiles, width, height, heads}
+func (s *solver) Parse(lines []string) error {
+  heights, err := grid.ParseFunc(lines, func(char rune, c grid.Coord) (int, error) {
+    if char < '0' || char > '9' { return 0, errs.Malformed("tile at x %d, y %d is not a height: '%c'", c.X, c.Y, char) }
+    return int(char - '0'), nil
+  })
+  if err != nil { return err }
+  s.terrain = newTerrain(heights)
+  return nil
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Ten, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Ten, ver, err))
-  }
-  terrain := newTerrain(&lines)
-  scoreSum, uniqueTrailsSum := solve(terrain)
-  fmt.Printf("Sum of all trail scores (part 1): %d\n", scoreSum)
-  fmt.Printf("Sum of all unique trails (part 2): %d\n", uniqueTrailsSum)
+// Both parts come out of the same trail exploration, so it only runs once
+func (s *solver) solve() {
+  if s.isSolved { return }
+  s.scoreSum, s.uniqueTrailsSum = solve(s.terrain)
+  s.isSolved = true
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  s.solve()
+  return registry.Int(s.scoreSum), nil
 }
 
+func (s *solver) Part2() (registry.Answer, error) {
+  s.solve()
+  return registry.Int(s.uniqueTrailsSum), nil
+}
+
+// The score of a trailhead is how many trail ends it reaches, and its rating how many different trails lead to them
 func solve(terrain *Terrain) (int, int) {
   scoreSum := 0
   uniqueTrailsSum := 0
-  for headIndex := range terrain.heads {
-    log := ""
-    trailEnds := make(map[int]struct{})
-    trailPath := Path(make([]int, 1))
-    uniqueTrails := make(map[string]struct{})
-    trailPath[0] = headIndex
-    exploreTrail(terrain, headIndex, headIndex, &log, &trailEnds, &uniqueTrails, trailPath)
-    if isDebug {
-      x := headIndex % terrain.width
-      y := headIndex / terrain.width
-      fmt.Printf("==================== Exploring trail starting at x %d, y %d ====================\n\n", x, y)
-      fmt.Print(log)
-      fmt.Printf("==================== Trail starting at x %d, y %d EXPLORED! Ends reached: %d, Unique trails: %d ====================\n\n", x, y, len(trailEnds), len(uniqueTrails))
-    }
+  for _, head := range terrain.heads {
+    if traceTrails.On() { traceTrails.Printf("==================== Exploring trail starting at x %d, y %d ====================\n\n", head.X, head.Y) }
+    trailEnds := make(map[grid.Coord]struct{})
+    uniqueTrails := exploreTrail(terrain, head, trailEnds)
+    if traceTrails.On() { traceTrails.Printf("==================== Trail starting at x %d, y %d EXPLORED! Ends reached: %d, Unique trails: %d ====================\n\n", head.X, head.Y, len(trailEnds), uniqueTrails) }
     scoreSum += len(trailEnds)
-    uniqueTrailsSum += len(uniqueTrails)
+    uniqueTrailsSum += uniqueTrails
   }
   return scoreSum, uniqueTrailsSum
 }
 
-func exploreTrail(terrain *Terrain, head int, currPos int, log *string, ends *map[int]struct{}, unique *map[string]struct{}, path Path) {
-  currVal := terrain.tiles[currPos]
-  x := currPos % terrain.width
-  y := currPos / terrain.width
-  left := x - 1
-  right := x + 1
-  up := y - 1
-  down := y + 1
-  if left >= 0 {
-    newPos := y * terrain.width + left
-    if terrain.tiles[newPos] == currVal + 1 {
-      *log += fmt.Sprintf("Going left from x %d, y %d to x %d, y %d (value %d).\n", x, y, left, y, currVal + 1)
-      path = append(path, newPos)
-      if terrain.tiles[newPos] == 9 { 
-        *log += "Trail end reached!\n\n"
-        _, alreadyMapped := (*ends)[newPos]; if !alreadyMapped { (*ends)[newPos] = struct{}{} }
-        pathKey := path.toString()
-        _, pathAlreadyRegistered := (*unique)[pathKey]; if !pathAlreadyRegistered { (*unique)[pathKey] = struct{}{} }
-      } else {
-        exploreTrail(terrain, head, newPos, log, ends, unique, path)
-      }
-    }
-  }
-  if right < terrain.width {
-    newPos := y * terrain.width + right
-    if terrain.tiles[newPos] == currVal + 1 {
-      *log += fmt.Sprintf("Going right from x %d, y %d to x %d, y %d (value %d).\n", x, y, right, y, currVal + 1)
-      path = append(path, newPos)
-      if terrain.tiles[newPos] == 9 {
-        *log += "Trail end reached!\n\n"
-        _, alreadyMapped := (*ends)[newPos]; if !alreadyMapped { (*ends)[newPos] = struct{}{} }
-        pathKey := path.toString()
-        _, pathAlreadyRegistered := (*unique)[pathKey]; if !pathAlreadyRegistered { (*unique)[pathKey] = struct{}{} }
-      } else {
-        exploreTrail(terrain, head, newPos, log, ends, unique, path)
-      }
-    }
-  }
-  if up >= 0 {
-    newPos := up * terrain.width + x
-    if terrain.tiles[newPos] == currVal + 1 {
-      *log += fmt.Sprintf("Going up from x %d, y %d to x %d, y %d (value %d).\n", x, y, x, up, currVal + 1)
-      path = append(path, newPos)
-      if terrain.tiles[newPos] == 9 {
-        *log += "Trail end reached!\n\n"
-        _, alreadyMapped := (*ends)[newPos]; if !alreadyMapped { (*ends)[newPos] = struct{}{} }
-        pathKey := path.toString()
-        _, pathAlreadyRegistered := (*unique)[pathKey]; if !pathAlreadyRegistered { (*unique)[pathKey] = struct{}{} }
-      } else {
-        exploreTrail(terrain, head, newPos, log, ends, unique, path)
-      }
-    }
-  }
-  if down < terrain.height {
-    newPos := down * terrain.width + x
-    if terrain.tiles[newPos] == currVal + 1 {
-      *log += fmt.Sprintf("Going down from x %d, y %d to x %d, y %d (value %d).\n", x, y, x, down, currVal + 1)
-      path = append(path, newPos)
-      if terrain.tiles[newPos] == 9 {
-        *log += "Trail end reached!\n\n"
-        _, alreadyMapped := (*ends)[newPos]; if !alreadyMapped { (*ends)[newPos] = struct{}{} }
-        pathKey := path.toString()
-        _, pathAlreadyRegistered := (*unique)[pathKey]; if !pathAlreadyRegistered { (*unique)[pathKey] = struct{}{} }
-      } else {
-        exploreTrail(terrain, head, newPos, log, ends, unique, path)
-      }
+// Follows every uphill step from pos, adding the trail ends reached to ends. Returns how many trails were followed
+// to an end, which are all different since heights only ever go up
+func exploreTrail(terrain *Terrain, pos grid.Coord, ends map[grid.Coord]struct{}) int {
+  currVal := terrain.heights.Get(pos)
+  trails := 0
+  for dir, next := range terrain.heights.Neighbours4(pos) {
+    if terrain.heights.Get(next) != currVal + 1 { continue }
+    if traceTrails.On() { traceTrails.Printf("Going %s from x %d, y %d to x %d, y %d (value %d).\n", dir, pos.X, pos.Y, next.X, next.Y, currVal + 1) }
+    if currVal + 1 == 9 {
+      if traceTrails.On() { traceTrails.Print("Trail end reached!\n\n") }
+      ends[next] = struct{}{}
+      trails++
+      continue
     }
+    trails += exploreTrail(terrain, next, ends)
   }
+  return trails
 }
@@ -2,32 +2,74 @@ package d11
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 	"strconv"
 	"strings"
 )
 
-var isDebug = false
-const isDebug2 = false
+var (
+  traceRules = trace.New(constants.Eleven, "rules", trace.Debug, "Rule applied to every stone")
+  traceStones = trace.New(constants.Eleven, "stones", trace.Debug, "Stones after each blink")
+)
+
+func init() {
+  registry.Register(constants.Eleven, func() registry.Solver { return &solver{} })
+}
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Eleven, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Eleven, ver, err))
+type solver struct {
+  numbers []int
+  blinksP1 int
+  blinksP2 int
+}
+
+func (s *solver) Params() []registry.Param {
+  return []registry.Param{
+    {Name: "blinks1", Default: 25, Usage: "Times to blink in part 1"},
+    {Name: "blinks2", Default: 75, Usage: "Times to blink in part 2"},
   }
-  stones := getStoneList(lines)
-  blinksP1 := 25
-  blinksP2 := 75
-  if isDebug2 { fmt.Printf("\n********** INITIAL STONES: %+v *************\n\n", stones) }
-  for i := range blinksP2 {
+}
+
+func (s *solver) SetParam(name string, value int) {
+  switch name {
+  case "blinks1":
+    s.blinksP1 = value
+  case "blinks2":
+    s.blinksP2 = value
+  }
+}
+
+func (s *solver) Parse(lines []string) error {
+  // Input only has 1 line
+  if len(lines) == 0 { return errs.Malformed("there are no stones") }
+  values := strings.Split(lines[0], " ")
+  s.numbers = make([]int, len(values))
+  for i, value := range values {
+    num, err := strconv.Atoi(value)
+    if err != nil { return errs.Malformed("stone %d: %v", i + 1, err) }
+    s.numbers[i] = num
+  }
+  return nil
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(s.countAfter(s.blinksP1)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Int(s.countAfter(s.blinksP2)), nil
+}
+
+// Blinking consumes the stone map, so every part starts from a fresh one
+func (s *solver) countAfter(blinks int) int {
+  stones := getStoneList(s.numbers)
+  if traceStones.On() { traceStones.Printf("\n********** INITIAL STONES: %+v *************\n\n", stones) }
+  for i := range blinks {
     stones =  blink(stones)
-    if isDebug2 { fmt.Printf("\n********** STONES AFTER %d BLINKS: %+v *************\n\n", i + 1, stones) }
-    if i == blinksP1 - 1 {
-      fmt.Printf("[PART 1] Total stones after %d blinks: %d\n", blinksP1, countStones(stones))
-    }
+    if traceStones.On() { traceStones.Printf("\n********** STONES AFTER %d BLINKS: %+v *************\n\n", i + 1, stones) }
   }
-  fmt.Printf("[PART 2] Total stones after %d blinks: %d\n", blinksP2, countStones(stones))
+  return countStones(stones)
 }
 
 func countStones(numbers *map[int]int) int {
@@ -48,7 +90,7 @@ func blink (numbers *map[int]int) *map[int]int {
     count, _ := (*numbers)[number]
     delete(*numbers, number)
     if number == 0 {
-      if isDebug { fmt.Print("Value is 0, so converting value to 1\n\n") }
+      if traceRules.On() { traceRules.Print("Value is 0, so converting value to 1\n\n") }
       _, exists := newNums[1]
       if !exists {
         newNums[1] = count
@@ -59,12 +101,12 @@ func blink (numbers *map[int]int) *map[int]int {
     }
     str := strconv.Itoa(number)
     if len(str) % 2 == 0 {
-      if isDebug { fmt.Print("Stone has even number of digits\n") }
+      if traceRules.On() { traceRules.Print("Stone has even number of digits\n") }
       strn1 := str[:len(str) / 2]
       strn2 := str[len(str) / 2:]
       n1, _ := strconv.Atoi(strn1)
       n2, _ := strconv.Atoi(strn2)
-      if isDebug { fmt.Printf("Value %d replaced by %d and %d\n\n", number, n1, n2) }
+      if traceRules.On() { traceRules.Printf("Value %d replaced by %d and %d\n\n", number, n1, n2) }
       _, exists := newNums[n1]
       if !exists { 
         newNums[n1] = count
@@ -79,7 +121,7 @@ func blink (numbers *map[int]int) *map[int]int {
       }
       continue
     }
-    if isDebug { fmt.Printf("Value %d replaced by %d (x 2024)\n\n", number, number * 2024) }
+    if traceRules.On() { traceRules.Printf("Value %d replaced by %d (x 2024)\n\n", number, number * 2024) }
     _, exists := newNums[number * 2024]
     if !exists {
       newNums[number * 2024] = count
@@ -90,13 +132,11 @@ func blink (numbers *map[int]int) *map[int]int {
   return &newNums
 }
 
-func getStoneList(lines []string) *map[int]int {
+func getStoneList(numbers []int) *map[int]int {
   sl := make(map[int]int)
-  // Input only has 1 line 
-  values := strings.Split(lines[0], " ")
-  for _, value := range values {
-    num, _ := strconv.Atoi(value)
+  for _, num := range numbers {
     _, exists := sl[num]
+
     if !exists {
       sl[num] = 1
     } else {
@@ -3,14 +3,19 @@ package d12
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
+	"aoc2k24/grid"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 )
 
 type Land struct {
-  height int
-  width int
-  plots []rune
+  plots *grid.Grid[rune]
+}
+
+// Whether c is inside the land and its plot grows the same plant as the one at from
+func (l Land) isSameRegion(from grid.Coord, c grid.Coord) bool {
+  plant, isInside := l.plots.Lookup(c)
+  return isInside && plant == l.plots.Get(from)
 }
 
 type RegionData struct {
@@ -47,129 +52,84 @@ func (rdm *RegionDataMap) update(rId, perimeter int) {
   }
 }
 
-func (l Land) iToCoord(i int) (int, int) {
-  return i % l.width, i / l.width
+var traceRegions = trace.New(constants.Twelve, "regions", trace.Debug, "Regions and the corners of their plots")
+
+func init() {
+  registry.Register(constants.Twelve, func() registry.Solver { return &solver{} })
 }
 
-func (l Land) coordToI(x, y int) int {
-  return y * l.width + x
+type solver struct {
+  land *Land
+  isSolved bool
+  priceP1 int
+  priceP2 int
 }
 
-func (l Land) isXOutOfBound(x int) bool {
-  return x < 0 || x >= l.width
+func (s *solver) Parse(lines []string) error {
+  plots, err := grid.Parse(lines)
+  if err != nil { return err }
+  s.land = &Land{plots}
+  return nil
 }
 
-func (l Land) isYOutOfBound(y int) bool {
-  return y < 0 || y >= l.height
+// Both parts come out of the same region scan, so it only runs once
+func (s *solver) solve() {
+  if s.isSolved { return }
+  s.priceP1, s.priceP2 = solve(s.land)
+  s.isSolved = true
 }
 
-const isDebug = false
+func (s *solver) Part1() (registry.Answer, error) {
+  s.solve()
+  return registry.Int(s.priceP1), nil
+}
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Twelve, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Twelve, ver, err))
-  }
-  land := getLand(lines)
-  priceP1, priceP2 := solve(land)
-  fmt.Printf("Price for fences (part 1): %d\n", priceP1)
-  fmt.Printf("Price for fences (part 2): %d\n", priceP2)
+func (s *solver) Part2() (registry.Answer, error) {
+  s.solve()
+  return registry.Int(s.priceP2), nil
 }
 
 func solve(land *Land) (int, int) {
   sumP1 := 0
   sumP2 := 0
-  visited := make(map[int]struct{})
-  for i := range len(land.plots) {
+  visited := make(map[grid.Coord]struct{})
+  for c := range land.plots.All() {
     area := 0
     perimeter := 0
     corners := 0
-    scanRegion(land, i, &visited, &area, &perimeter, &corners)
+    scanRegion(land, c, visited, &area, &perimeter, &corners)
     sumP1 += area * perimeter
     sumP2 += area * corners
-    if isDebug { fmt.Printf("==== END REGION ====\n\n") }
+    if traceRegions.On() { traceRegions.Printf("==== END REGION ====\n\n") }
   }
   return sumP1, sumP2
 }
 
-func scanRegion(land *Land, i int, visited *map[int]struct{}, area *int, perimeter *int, corners *int) {
-  _, isVisited := (*visited)[i]
+// Flood fills the region of c. A region has as many sides as corners, which is what part 2 counts
+func scanRegion(land *Land, c grid.Coord, visited map[grid.Coord]struct{}, area *int, perimeter *int, corners *int) {
+  _, isVisited := visited[c]
   if isVisited { return }
-  (*visited)[i] = struct{}{}
-  x, y := land.iToCoord(i)
+  visited[c] = struct{}{}
   *area += 1
   *perimeter += 4
-  upY := y - 1
-  downY := y + 1
-  leftX := x - 1
-  rightX := x + 1
-  if upY >= 0 {
-    newI := land.coordToI(x, upY)
-    if land.plots[i] == land.plots[newI] {
-      *perimeter--
-      scanRegion(land, newI, visited, area, perimeter, corners)
-    }
-  }
-  if downY < land.height {
-    newI := land.coordToI(x, downY)
-    if land.plots[i] == land.plots[newI] {
-      *perimeter--
-      scanRegion(land, newI, visited, area, perimeter, corners)
-    }
+  for _, next := range land.plots.Neighbours4(c) {
+    if !land.isSameRegion(c, next) { continue }
+    *perimeter--
+    scanRegion(land, next, visited, area, perimeter, corners)
   }
-  if leftX >= 0 {
-    newI := land.coordToI(leftX, y)
-    if land.plots[i] == land.plots[newI] {
-      *perimeter--
-      scanRegion(land, newI, visited, area, perimeter, corners)
-    }
-  }
-  if rightX < land.width {
-    newI := land.coordToI(rightX, y)
-    if land.plots[i] == land.plots[newI] {
-      *perimeter--
-      scanRegion(land, newI, visited, area, perimeter, corners)
-    }
-  }
-  cornerFlags := []bool{
-    // Outer corners when against one edge
-    upY == -1 && leftX >= 0 && land.plots[i] != land.plots[land.coordToI(leftX, y)],
-    upY == -1 && rightX < land.width && land.plots[i] != land.plots[land.coordToI(rightX, y)],
-    downY == land.height && leftX >= 0 && land.plots[i] != land.plots[land.coordToI(leftX, y)],
-    downY == land.height && rightX < land.width && land.plots[i] != land.plots[land.coordToI(rightX, y)],
-    upY >= 0 && leftX == -1 && land.plots[i] != land.plots[land.coordToI(x, upY)],
-    downY < land.height && leftX == -1 && land.plots[i] != land.plots[land.coordToI(x, downY)],
-    upY >= 0 && rightX == land.width && land.plots[i] != land.plots[land.coordToI(x, upY)],
-    downY < land.height && rightX == land.width && land.plots[i] != land.plots[land.coordToI(x, downY)],
-    // Outer corners at the corners of the map
-    upY == -1 && leftX == -1,
-    upY == -1 && rightX == land.width,
-    downY == land.height && rightX == land.width,
-    downY == land.height && leftX == -1,
-    // Outer corners away from edges
-    upY >= 0 && leftX >= 0 && land.plots[i] != land.plots[land.coordToI(x, upY)] && land.plots[i] != land.plots[land.coordToI(leftX, y)],
-    downY < land.height && leftX >= 0 && land.plots[i] != land.plots[land.coordToI(x, downY)] && land.plots[i] != land.plots[land.coordToI(leftX, y)],
-    upY >= 0 && rightX < land.width && land.plots[i] != land.plots[land.coordToI(x, upY)] && land.plots[i] != land.plots[land.coordToI(rightX, y)],
-    downY < land.height && rightX < land.width && land.plots[i] != land.plots[land.coordToI(x, downY)] && land.plots[i] != land.plots[land.coordToI(rightX, y)],
-    // Inner corners
-    upY >= 0 && leftX >= 0 && land.plots[i] == land.plots[land.coordToI(x, upY)] && land.plots[i] == land.plots[land.coordToI(leftX, y)] && land.plots[i] != land.plots[land.coordToI(leftX, upY)],
-    upY >= 0 && rightX < land.width && land.plots[i] == land.plots[land.coordToI(x, upY)] && land.plots[i] == land.plots[land.coordToI(rightX, y)] && land.plots[i] != land.plots[land.coordToI(rightX, upY)],
-    downY < land.height && rightX < land.width && land.plots[i] == land.plots[land.coordToI(x, downY)] && land.plots[i] == land.plots[land.coordToI(rightX, y)] && land.plots[i] != land.plots[land.coordToI(rightX, downY)],
-    downY < land.height && leftX >= 0 && land.plots[i] == land.plots[land.coordToI(x, downY)] && land.plots[i] == land.plots[land.coordToI(leftX, y)] && land.plots[i] != land.plots[land.coordToI(leftX, downY)],
-  }
-  debugCorners := 0
-  for _, isCorner := range cornerFlags {
-    if isCorner { *corners++; debugCorners++ }
-  }
-  if isDebug { fmt.Printf("Tile at x %d, y %d (%c) has %d corners (%d corners so far)\n", x, y, land.plots[i], debugCorners, *corners) }
-}
-
-func getLand(lines []string) *Land {
-  l := Land{len(lines), len(lines[0]), make([]rune, len(lines) * len(lines[0]))}
-  for y, line := range lines {
-    for x, char := range line {
-      l.plots[l.coordToI(x, y)] = rune(char)
-    }
+  // Each corner of the plot is checked through the two sides that meet there. If neither neighbour is in the region
+  // it's an outer corner, and if both are but the diagonal one isn't, an inner one
+  plotCorners := 0
+  for _, dir := range grid.Directions {
+    side := c.Step(dir)
+    otherSide := c.Step(dir.TurnRight())
+    diagonal := side.Step(dir.TurnRight())
+    isSideSame := land.isSameRegion(c, side)
+    isOtherSideSame := land.isSameRegion(c, otherSide)
+    isOuterCorner := !isSideSame && !isOtherSideSame
+    isInnerCorner := isSideSame && isOtherSideSame && !land.isSameRegion(c, diagonal)
+    if isOuterCorner || isInnerCorner { plotCorners++ }
   }
-  return &l
+  *corners += plotCorners
+  if traceRegions.On() { traceRegions.Printf("Tile at x %d, y %d (%c) has %d corners (%d corners so far)\n", c.X, c.Y, land.plots.Get(c), plotCorners, *corners) }
 }
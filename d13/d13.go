@@ -2,15 +2,15 @@ package d13
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
-const isDebug = false
-const isPart2 = true
-const part2Multiplier = 10000000000000
+var traceMachines = trace.New(constants.Thirteen, "machines", trace.Debug, "Tokens needed to win every machine")
 const aCost = 3
 const bCost = 1
 
@@ -31,120 +31,153 @@ func (m Machine) toString() string {
   return fmt.Sprintf("Prize: X=%d, Y=%d | Button A: X+%d, Y+%d | Button B: X+%d, Y+%d\n", m.prizeX, m.prizeY, m.buttonA.xInc, m.buttonA.yInc, m.buttonB.xInc, m.buttonB.yInc)
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Thirteen, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Thirteen, ver, err))
+func init() {
+  registry.Register(constants.Thirteen, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  machines *[]Machine
+  prizeOffset int
+  // The machines as solved by each part, kept for Extras
+  solved map[constants.PartIndex][]Machine
+}
+
+// Whether each machine can be won in the part, and for how many tokens
+type MachineExtras struct {
+  Machine int `json:"machine"`
+  Winnable bool `json:"winnable"`
+  Tokens int `json:"tokens,omitempty"`
+}
+
+func (s *solver) Params() []registry.Param {
+  return []registry.Param{
+    {Name: "offset", Default: 10000000000000, Usage: "Added to both prize coordinates in part 2"},
+  }
+}
+
+func (s *solver) SetParam(name string, value int) {
+  if name == "offset" { s.prizeOffset = value }
+}
+
+func (s *solver) Parse(lines []string) error {
+  var err error
+  s.machines, err = getMachines(lines)
+  return err
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  tokens, err := s.run(constants.Part1, 0)
+  return registry.Int(tokens), err
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  tokens, err := s.run(constants.Part2, s.prizeOffset)
+  return registry.Int(tokens), err
+}
+
+func (s *solver) Extras(part constants.PartIndex) any {
+  extras := make([]MachineExtras, len(s.solved[part]))
+  for i, machine := range s.solved[part] {
+    extras[i] = MachineExtras{i + 1, machine.tokensToWin >= 0, max(machine.tokensToWin, 0)}
+  }
+  return extras
+}
+
+// Each part works over its own copy of the machines, with the prizes moved by the given offset
+func (s *solver) run(part constants.PartIndex, prizeOffset int) (int, error) {
+  machines := make([]Machine, len(*s.machines))
+  for i, machine := range *s.machines {
+    machine.prizeX += prizeOffset
+    machine.prizeY += prizeOffset
+    machines[i] = machine
   }
-  machines := getMachines(lines)
-  solve(machines)
+  err := solve(&machines)
+  if err != nil { return 0, err }
   winnable := 0
   tokens := 0
-  for i := range len(*machines) {
-    if (*machines)[i].tokensToWin >= 0 {
+  for i := range len(machines) {
+    if machines[i].tokensToWin >= 0 {
       winnable++
-      tokens += (*machines)[i].tokensToWin
+      tokens += machines[i].tokensToWin
     }
-    fmt.Printf("Machine %d: Winnable? %v | Tokens: %d\n", i + 1, (*machines)[i].tokensToWin >= 0, (*machines)[i].tokensToWin)
+    if traceMachines.On() { traceMachines.Printf("Machine %d: Winnable? %v | Tokens: %d\n", i + 1, machines[i].tokensToWin >= 0, machines[i].tokensToWin) }
   }
-  fmt.Printf("\nTotal winnable: %d | Total tokens: %d\n", winnable, tokens)
+  if traceMachines.On() { traceMachines.Printf("\nTotal winnable: %d | Total tokens: %d\n", winnable, tokens) }
+  if s.solved == nil { s.solved = make(map[constants.PartIndex][]Machine) }
+  s.solved[part] = machines
+  return tokens, nil
 }
 
-func solve(machines *[]Machine) {
+func solve(machines *[]Machine) error {
   for i, m := range *machines {
-    (*machines)[i].tokensToWin = solveMachine(&m)
+    tokens, err := solveMachine(&m)
+    if err != nil { return fmt.Errorf("machine %d: %w", i + 1, err) }
+    (*machines)[i].tokensToWin = tokens
   }
+  return nil
 }
 
-// Solved using Cramer's Rule. Thanks to Grant Riordan (https://dev.to/grantdotdev) for introducing me to this approach
-func solveMachine(m *Machine) int {
+// Solved using Cramer's Rule. Thanks to Grant Riordan (https://dev.to/grantdotdev) for introducing me to this approach.
+// Tokens are -1 if the machine can't be won
+func solveMachine(m *Machine) (int, error) {
   // Find determinants
   det := m.buttonA.xInc * m.buttonB.yInc - m.buttonB.xInc * m.buttonA.yInc
+  if det == 0 { return solveCollinear(m) }
   detX := m.prizeX * m.buttonB.yInc - m.prizeY * m.buttonB.xInc
   detY := m.prizeY * m.buttonA.xInc - m.prizeX * m.buttonA.yInc
   // Only solvable is detX / det and detY / det (amount of button presses) are both integers
   isSolvable := detX % det == 0 && detY % det == 0
-  if !isSolvable { return - 1 }
+  if !isSolvable { return -1, nil }
   aPresses := detX / det
   bPresses := detY / det
-  return aPresses * aCost + bPresses * bCost
+  return aPresses * aCost + bPresses * bCost, nil
 }
 
-// Alternative recursive method with memoization used for part 1. Impractical for part 2
-func solveRecursive(machines *[]Machine) {
-  log := ""
-  for i, m := range *machines {
-    mem := make(map[string]int)
-    (*machines)[i].tokensToWin = solveMachineRecursive(&m, 0, 0, 0, &log, &mem)
-    log += "\n"
-  }
-  if isDebug { fmt.Print(log) }
+// Buttons moving along the same line (a determinant of 0) can only reach prizes on that line. Those can be reached
+// with many combinations of presses, which Cramer's Rule can't choose the cheapest of
+func solveCollinear(m *Machine) (int, error) {
+  isOnLine := m.prizeX * m.buttonA.yInc == m.prizeY * m.buttonA.xInc && m.prizeX * m.buttonB.yInc == m.prizeY * m.buttonB.xInc
+  if !isOnLine { return -1, nil }
+  return 0, errs.Unsolvable("both buttons move along the line to the prize, so it can be won with many combinations of presses")
 }
 
-func solveMachineRecursive(m *Machine, x, y, toks int, log *string, mem *map[string]int) int {
-  key := fmt.Sprintf("x%d-y%d", x, y)
-  memo, isMemo := (*mem)[key]; if isMemo { 
-    return memo
-  }
-  if x > m.prizeX || y > m.prizeY || x == m.prizeX && y < m.prizeY || x < m.prizeX && y == m.prizeY {
-    *log += "Target overshot, returning -1\n"
-    return -1
-  }
-  if x == m.prizeX && y == m.prizeY {
-    *log += fmt.Sprintf("Target reached! Returning cost of %d\n", toks)
-    return toks
-  }
-  resB := solveMachineRecursive(m, x + m.buttonB.xInc, y + m.buttonB.yInc, toks + bCost, log, mem)
-  resA := solveMachineRecursive(m, x + m.buttonA.xInc, y + m.buttonA.yInc, toks + aCost, log, mem)
-  res := -1
-  if resA > -1 && resB > -1 {
-    if resA < resB {
-      res = resA
-    } else {
-      res = resB
-    }
-  } else if resA > -1 || resB > -1 {
-    if resB == -1 {
-      res = resA
-    } else {
-      res = resB
-    }
-  }
-  (*mem)[key] = res
-  return res
-}
-
-func getMachines(lines []string) *[]Machine {
+func getMachines(lines []string) (*[]Machine, error) {
   machines := make([]Machine, 0)
   machine := Machine{0, 0, Button{0, 0}, Button{0, 0}, -1}
-  for _, line := range lines {
+  for i, line := range lines {
     if len(line) == 0 { continue }
     parts := strings.Split(line, ": ")
+    if len(parts) != 2 { return nil, errs.Malformed("line %d: %q", i + 1, line) }
+    x, y, err := parseXY(parts[1])
+    if err != nil { return nil, errs.Malformed("line %d: %v", i + 1, err) }
     if parts[0] == "Button A" {
-      comp := strings.Split(parts[1], ", ")
-      xInc, _ := strconv.Atoi(comp[0][2:])
-      yInc, _ := strconv.Atoi(comp[1][2:])
-      machine.buttonA.xInc = xInc
-      machine.buttonA.yInc = yInc
+      machine.buttonA.xInc = x
+      machine.buttonA.yInc = y
     } else if parts[0] == "Button B" {
-      comp := strings.Split(parts[1], ", ")
-      xInc, _ := strconv.Atoi(comp[0][2:])
-      yInc, _ := strconv.Atoi(comp[1][2:])
-      machine.buttonB.xInc = xInc
-      machine.buttonB.yInc = yInc
-    } else {
-      comp := strings.Split(parts[1], ", ")
-      x, _ := strconv.Atoi(comp[0][2:])
-      y, _ := strconv.Atoi(comp[1][2:])
+      machine.buttonB.xInc = x
+      machine.buttonB.yInc = y
+    } else if parts[0] == "Prize" {
       machine.prizeX = x
       machine.prizeY = y
-      if isPart2 {
-        machine.prizeX += part2Multiplier
-        machine.prizeY += part2Multiplier
-      }
       machines = append(machines, machine)
       machine = Machine{0, 0, Button{0, 0}, Button{0, 0}, -1}
+    } else {
+      return nil, errs.Malformed("line %d: unknown entry %q", i + 1, parts[0])
     }
   }
-  return &machines
+  return &machines, nil
 }
+
+// Parses both "X+94, Y+34" (buttons) and "X=8400, Y=5400" (prizes)
+func parseXY(values string) (int, int, error) {
+  comp := strings.Split(values, ", ")
+  if len(comp) != 2 || len(comp[0]) < 3 || len(comp[1]) < 3 {
+    return 0, 0, fmt.Errorf("expected X and Y values, got %q", values)
+  }
+  x, err := strconv.Atoi(comp[0][2:])
+  if err != nil { return 0, 0, err }
+  y, err := strconv.Atoi(comp[1][2:])
+  if err != nil { return 0, 0, err }
+  return x, y, nil
+}
+
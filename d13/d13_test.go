@@ -0,0 +1,44 @@
+package d13_test
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/d13"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func machine(a string, b string, prize string) []string {
+  return []string{"Button A: " + a, "Button B: " + b, "Prize: " + prize, ""}
+}
+
+func TestMachines(t *testing.T) {
+  lines := slices.Concat(
+    machine("X+94, Y+34", "X+22, Y+67", "X=8400, Y=5400"),
+    machine("X+26, Y+66", "X+67, Y+21", "X=12748, Y=12176"),
+    // Buttons moving along the same line can't reach a prize off it
+    machine("X+1, Y+1", "X+2, Y+2", "X=5, Y=6"),
+  )
+  entry, _ := registry.Get(constants.Thirteen)
+  solver := entry.New()
+  err := solver.Parse(lines)
+  if err != nil { t.Fatal(err) }
+  answer, err := solver.Part1()
+  if err != nil { t.Fatal(err) }
+  if answer.String() != "280" { t.Errorf("got %s tokens, expected 280", answer) }
+  expected := []d13.MachineExtras{{Machine: 1, Winnable: true, Tokens: 280}, {Machine: 2}, {Machine: 3}}
+  extras := registry.Extras(solver, constants.Part1).([]d13.MachineExtras)
+  if !slices.Equal(extras, expected) { t.Errorf("got machines %+v, expected %+v", extras, expected) }
+}
+
+// On their line, the prize could be won with many combinations of presses
+func TestCollinearButtons(t *testing.T) {
+  entry, _ := registry.Get(constants.Thirteen)
+  solver := entry.New()
+  err := solver.Parse(machine("X+1, Y+1", "X+2, Y+2", "X=6, Y=6"))
+  if err != nil { t.Fatal(err) }
+  _, err = solver.Part1()
+  if !errors.Is(err, errs.ErrPartUnsolvable) { t.Errorf("got %v, expected the part to be unsolvable", err) }
+}
@@ -2,13 +2,18 @@ package d14
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
-const isDebug = false
+var (
+  traceMoves = trace.New(constants.Fourteen, "moves", trace.Debug, "Robot moves on every second")
+  traceRender = trace.New(constants.Fourteen, "render", trace.Verbose, "Robots forming the tree found in part 2")
+)
 
 type VelocityMap map[int][2]int
 type PositionMap map[string][]int
@@ -31,27 +36,58 @@ func (p PositionMap) get(x, y int) []int {
   return ids
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Fourteen, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Fourteen, ver, err))
+func init() {
+  registry.Register(constants.Fourteen, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  width int
+  height int
+  vels *VelocityMap
+  posits *PositionMap
+}
+
+func (s *solver) Params() []registry.Param {
+  return []registry.Param{
+    {Name: "width", Default: 101, Min: 1, Usage: "Width of the area the robots move in"},
+    {Name: "height", Default: 103, Min: 1, Usage: "Height of the area the robots move in"},
   }
-  areaData := strings.Split(lines[0], ",")
-  width, _ := strconv.Atoi(areaData[0])
-  height, _ := strconv.Atoi(areaData[1])
-  steps := width * height
+}
+
+func (s *solver) SetParam(name string, value int) {
+  switch name {
+  case "width":
+    s.width = value
+  case "height":
+    s.height = value
+  }
+}
+
+func (s *solver) Parse(lines []string) error {
+  if len(lines) == 0 { return errs.Malformed("input is empty") }
+  var err error
+  s.vels, s.posits, err = parseInput(&lines)
+  return err
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
   part1Steps := 100
-  lines = lines[1:]
-  vels, posits := parseInput(&lines)
-  var part1 *PositionMap
+  posits := s.posits
+  for range part1Steps {
+    if traceMoves.On() { traceMoves.Print("\n\n ************** \n\n") }
+    posits = move(s.vels, posits, s.width, s.height)
+  }
+  return registry.Int(computeSafetyFactor(posits, s.width, s.height)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  steps := s.width * s.height
+  posits := s.posits
   var tree *PositionMap
   var part2Steps int
   for i := range steps {
-    if isDebug { fmt.Print("\n\n ************** \n\n") }
-    posits = move(vels, posits, width, height)
-    if i == part1Steps - 1 {
-      part1 = posits
-    }
+    if traceMoves.On() { traceMoves.Print("\n\n ************** \n\n") }
+    posits = move(s.vels, posits, s.width, s.height)
     // 33 was found experimenting and watching the resulting pattern
     // Started at 40, then gradually decreased until a candidate was found
     if hasHorizontallyAlignedRobots(posits, 33) {
@@ -60,10 +96,14 @@ func Init(ver constants.VersionIndex) {
       break
     }
   }
-  safetyFactor := computeSafetyFactor(part1, width, height)
-  fmt.Printf("Safety Factor (part 1): %d\n\n", safetyFactor)
-  fmt.Printf("Tree candidate (part 2): %d seconds, visual:\n", part2Steps)
-  render(tree, width, height)
+  if tree == nil {
+    return registry.Answer{}, errs.Unsolvable("no tree candidate after %d seconds, when robots are back to their initial positions", steps)
+  }
+  if traceRender.On() {
+    traceRender.Printf("Tree candidate (part 2): %d seconds, visual:\n", part2Steps)
+    render(tree, s.width, s.height)
+  }
+  return registry.Int(part2Steps), nil
 }
 
 func hasHorizontallyAlignedRobots(posits *PositionMap, amount int) bool {
@@ -125,13 +165,13 @@ func move(vels *VelocityMap, posits *PositionMap, width, height int) *PositionMa
   }
   for _, key := range keys {
     oldX, oldY := keyToCoord(key)
-    if isDebug { fmt.Printf("Computing moves for x %d, y %d\n", oldX, oldY) }
+    if traceMoves.On() { traceMoves.Printf("Computing moves for x %d, y %d\n", oldX, oldY) }
     ids, _ := (*posits)[key]
     for _, robotId := range ids {
       vels := (*vels)[robotId]
       velX := vels[0]
       velY := vels[1]
-      if isDebug { fmt.Printf("Computing move robot %d (pos: %d, %d | vel: %d, %d): ", robotId, oldX, oldY, velX, velY) }
+      if traceMoves.On() { traceMoves.Printf("Computing move robot %d (pos: %d, %d | vel: %d, %d): ", robotId, oldX, oldY, velX, velY) }
       x := oldX + velX
       y := oldY + velY
       if x >= width {
@@ -144,28 +184,39 @@ func move(vels *VelocityMap, posits *PositionMap, width, height int) *PositionMa
       } else if y < 0 {
         y = height + y
       }
-      if isDebug { fmt.Printf("New position %d, %d\n", x, y) }
+      if traceMoves.On() { traceMoves.Printf("New position %d, %d\n", x, y) }
       newPos.add(robotId, x, y)
     }
   }
   return &newPos
 }
 
-func parseInput(lines *[]string) (*VelocityMap, *PositionMap) {
+func parseInput(lines *[]string) (*VelocityMap, *PositionMap, error) {
   vels := make(VelocityMap)
   posits := make(PositionMap)
   for id, line := range *lines {
     parts := strings.Split(line, " ")
-    pos := strings.Split(parts[0][2:], ",")
-    vel := strings.Split(parts[1][2:], ",")
-    posX, _ := strconv.Atoi(pos[0])
-    posY, _ := strconv.Atoi(pos[1])
-    velX, _ := strconv.Atoi(vel[0])
-    velY, _ := strconv.Atoi(vel[1])
+    if len(parts) != 2 || !strings.HasPrefix(parts[0], "p=") || !strings.HasPrefix(parts[1], "v=") {
+      return nil, nil, errs.Malformed("robot %d should look like p=x,y v=x,y: %q", id, line)
+    }
+    posX, posY, err := parsePair(parts[0][2:])
+    if err != nil { return nil, nil, errs.Malformed("robot %d position: %v", id, err) }
+    velX, velY, err := parsePair(parts[1][2:])
+    if err != nil { return nil, nil, errs.Malformed("robot %d velocity: %v", id, err) }
     vels[id] = [2]int{velX, velY}
     posits.add(id, posX, posY)
   }
-  return &vels, &posits
+  return &vels, &posits, nil
+}
+
+func parsePair(pair string) (int, int, error) {
+  values := strings.Split(pair, ",")
+  if len(values) != 2 { return 0, 0, fmt.Errorf("expected two comma separated values, got %q", pair) }
+  x, err := strconv.Atoi(values[0])
+  if err != nil { return 0, 0, err }
+  y, err := strconv.Atoi(values[1])
+  if err != nil { return 0, 0, err }
+  return x, y, nil
 }
 
 func coordToKey(x, y int) string {
@@ -180,18 +231,18 @@ func keyToCoord(key string) (int, int) {
 }
 
 func render(posits *PositionMap, width, height int) {
-  fmt.Print("\n")
+  traceRender.Print("\n")
   for y := range height {
     for x := range width {
       key := coordToKey(x, y)
       ids, exists := (*posits)[key]
       if !exists {
-        fmt.Print(".")
+        traceRender.Print(".")
         continue
       }
-      fmt.Print(len(ids))
+      traceRender.Print(len(ids))
     }
-    fmt.Print("\n")
+    traceRender.Print("\n")
   }
-  fmt.Print("\n\n")
+  traceRender.Print("\n\n")
 }
@@ -2,108 +2,108 @@ package d15
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
+	"aoc2k24/errs"
+	"aoc2k24/grid"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
+	"cmp"
 	"fmt"
-	"os"
 	"os/exec"
 	"slices"
-	"sort"
+	"strings"
 	// "time"
 )
 
-const isDebug = false
-const useDebugData = false
-const isAnimated = false
-
-type Direction int
-
-const (
-  Up Direction = iota
-  Right
-  Down
-  Left
+var (
+  traceMoves = trace.New(constants.Fifteen, "moves", trace.Debug, "Robot and box moves of part 2")
+  traceAnimation = trace.New(constants.Fifteen, "animation", trace.Verbose, "Warehouse redrawn on every move of part 2")
 )
 
+// Robot moves as written in the input, in the same order as grid.Directions
+const moveChars = "^>v<"
+
 type Warehouse struct {
   width int
   height int
-  boxes map[int]struct{}
-  walls map[int]struct{}
+  boxes map[grid.Coord]struct{}
+  walls map[grid.Coord]struct{}
 }
 
 type Warehouse2 struct {
   width int
   height int
-  boxes map[int]*Box
-  walls map[int]struct{}
+  boxes map[grid.Coord]*Box
+  walls map[grid.Coord]struct{}
 }
 
 type Robot struct {
-  position int
-  moves []Direction
+  position grid.Coord
+  moves []grid.Direction
 }
 
 type Box struct {
-  start int
-  end int
+  start grid.Coord
+  end grid.Coord
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Fifteen, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Fifteen, ver, err))
-  }
-  warehouse, robot := parseInput(lines)
-  warehouse2, robot2 := parseInputPart2(lines)
-  if isDebug {
-    fmt.Printf("Warehouse: width %d, height %d\n", warehouse2.width, warehouse2.height)
-    fmt.Printf("Boxes: %+v\n", warehouse2.boxes)
-    fmt.Printf("Walls: %+v\n", warehouse2.walls)
-    fmt.Printf("Robot: %+v\n", *robot2)
-    renderPart2(warehouse2, robot2)
+func init() {
+  registry.Register(constants.Fifteen, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  warehouse *Warehouse
+  robot *Robot
+  warehouse2 *Warehouse2
+  robot2 *Robot
+}
+
+func (s *solver) Parse(lines []string) error {
+  err := validateInput(lines)
+  if err != nil { return err }
+  // Part 1 warehouse has to be parsed first, since widening it for part 2 rewrites the lines in place
+  s.warehouse, s.robot = parseInput(lines)
+  s.warehouse2, s.robot2 = parseInputPart2(lines)
+  if traceMoves.On() {
+    traceMoves.Printf("Warehouse: width %d, height %d\n", s.warehouse2.width, s.warehouse2.height)
+    traceMoves.Printf("Boxes: %+v\n", s.warehouse2.boxes)
+    traceMoves.Printf("Walls: %+v\n", s.warehouse2.walls)
+    traceMoves.Printf("Robot: %+v\n", *s.robot2)
+    renderPart2(s.warehouse2, s.robot2)
   }
-  gpsSum := solve(warehouse, robot)
-  gpsSumPart2 := solvePart2(warehouse2, robot2)
-  fmt.Printf("GPS coordinate sum (part 1): %d\n", gpsSum)
-  fmt.Printf("GPS coordinate sum (part 2): %d\n", gpsSumPart2)
+  return nil
 }
 
-func generateDebugData(isReverse bool) []string {
-  warehouse := []string{
-    "##################",
-    "##..............##",
-    "##..............##",
-    "##..[][]..[][]..##",
-    "##...[]....[]...##",
-    "##..[]..[][]....##",
-    "##...[]..[].....##",
-    "##....[][]......##",
-    "##.....[].......##",
-    "##......@.......##",
-    "##..............##",
-    "##..............##",
-    "##################",
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(solve(s.warehouse, s.robot)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Int(solvePart2(s.warehouse2, s.robot2)), nil
+}
+
+// The warehouse map and the robot moves are separated by an empty line, and the map must hold exactly one robot
+func validateInput(lines []string) error {
+  separator := slices.Index(lines, "")
+  if separator < 1 { return errs.Malformed("expected the warehouse map, an empty line and then the robot moves") }
+  robots := 0
+  for y, line := range lines[:separator] {
+    if len(line) != len(lines[0]) { return errs.Malformed("warehouse row %d is %d tiles wide, expected %d", y + 1, len(line), len(lines[0])) }
+    for x, char := range line {
+      if !strings.ContainsRune("#.O@", char) { return errs.Malformed("unknown tile '%c' at x %d, y %d", char, x, y) }
+      if char == '@' { robots++ }
+    }
   }
-  instructions := "^^"
-  if isReverse {
-    slices.Reverse(warehouse)
-    instructions = "vv"
+  if robots != 1 { return errs.Malformed("warehouse should have exactly one robot, found %d", robots) }
+  for i, line := range lines[separator + 1:] {
+    for _, char := range line {
+      if !strings.ContainsRune(moveChars, char) { return errs.Malformed("unknown move '%c' in line %d", char, separator + i + 2) }
+    }
   }
-  warehouse = append(warehouse, "")
-  return append(warehouse, instructions)
+  return nil
 }
 
-func move(i int, w *Warehouse, dir Direction, r *Robot) bool {
-  j := i + 1
-  switch dir {
-  case Up:
-    j = i - w.width
-  case Down:
-    j = i + w.width
-  case Left:
-    j = i - 1
-  }
-  
+func move(i grid.Coord, w *Warehouse, dir grid.Direction, r *Robot) bool {
+  j := i.Step(dir)
   _, isNextWall := w.walls[j]; if isNextWall { return false }
   _, isNextBox := w.boxes[j];
   // If next is box (else is empty space)
@@ -120,47 +120,30 @@ func move(i int, w *Warehouse, dir Direction, r *Robot) bool {
   return true
 }
 
-func canMove(pos []int, w *Warehouse2, dir Direction, r *Robot, boxesToMove *map[int]struct{}) bool {
+func canMove(pos []grid.Coord, w *Warehouse2, dir grid.Direction, r *Robot, boxesToMove *map[grid.Coord]struct{}) bool {
   result := true
   for _, i := range pos {
-    if isDebug { fmt.Printf("Checking if pos %d can move\n", i) }
-    nextPos := make([]int, 1)
+    if traceMoves.On() { traceMoves.Printf("Checking if pos %v can move\n", i) }
+    nextPos := []grid.Coord{i.Step(dir)}
     box, isCurrentBox := w.boxes[i]
     if isCurrentBox {
-      nextPos[0] = box.end + 1
-    } else {
-      nextPos[0] = i + 1
-    }
-    switch dir {
-    case Up:
-      if isCurrentBox {
-        nextPos[0] = box.start - w.width
-        nextPos = append(nextPos, box.end - w.width)
-      } else {
-        nextPos[0] = i - w.width
-      }
-    case Down:
-      if isCurrentBox {
-        nextPos[0] = box.start + w.width
-        nextPos = append(nextPos, box.end + w.width)
-      } else {
-        nextPos[0] = i + w.width
-      }
-    case Left:
-      if isCurrentBox {
-        nextPos[0] = box.start - 1
-      } else {
-        nextPos[0] = i - 1
+      switch dir {
+      case grid.Up, grid.Down:
+        nextPos = []grid.Coord{box.start.Step(dir), box.end.Step(dir)}
+      case grid.Left:
+        nextPos[0] = box.start.Step(dir)
+      default:
+        nextPos[0] = box.end.Step(dir)
       }
     }
-    if isCurrentBox && isDebug { fmt.Printf("This is a box starting at %d and ending at %d\n", box.start, box.end) }
-    if isDebug { fmt.Printf("Next position(s) are: %+v\n", nextPos) }
+    if isCurrentBox && traceMoves.On() { traceMoves.Printf("This is a box starting at %v and ending at %v\n", box.start, box.end) }
+    if traceMoves.On() { traceMoves.Printf("Next position(s) are: %+v\n", nextPos) }
 
     isNextWall := false
     for _, j := range nextPos {
       _, isNextWall = w.walls[j]
       if isNextWall {
-        if isDebug { fmt.Printf("Next position %d is a wall\n\n", j) }
+        if traceMoves.On() { traceMoves.Printf("Next position %v is a wall\n\n", j) }
         return false
       }
     }
@@ -172,79 +155,56 @@ func canMove(pos []int, w *Warehouse2, dir Direction, r *Robot, boxesToMove *map
       nextBox2, isNextBox2 = w.boxes[nextPos[1]]
     }
     if isNextBox1 {
-      if isDebug { fmt.Printf("Will check if box starting at %d and ending at %d can be moved\n", nextBox1.start, nextBox1.end) }
+      if traceMoves.On() { traceMoves.Printf("Will check if box starting at %v and ending at %v can be moved\n", nextBox1.start, nextBox1.end) }
       (*boxesToMove)[nextBox1.start] = struct{}{}
       nextPos[0] = nextBox1.start
     }
     if isNextBox1 && isNextBox2 && nextBox1.start != nextBox2.start || !isNextBox1 && isNextBox2 {
-      if isDebug { fmt.Printf("Will check if box starting at %d and ending at %d can be moved\n", nextBox2.start, nextBox2.end) }
+      if traceMoves.On() { traceMoves.Printf("Will check if box starting at %v and ending at %v can be moved\n", nextBox2.start, nextBox2.end) }
       (*boxesToMove)[nextBox2.start] = struct{}{}
       nextPos[1] = nextBox2.start
     }
-    if isDebug { fmt.Print("\n") }
+    if traceMoves.On() { traceMoves.Print("\n") }
     if isNextBox1 || isNextBox2 { result = canMove(nextPos, w, dir, r, boxesToMove) }
   }
 
   // If next is not wall and not box, then it must be open space
-  if isDebug { fmt.Print("All next positions are open space\n\n") }
+  if traceMoves.On() { traceMoves.Print("All next positions are open space\n\n") }
   return result
 }
 
-func movePart2(w *Warehouse2, dir Direction, r *Robot) bool {
-  if isDebug { fmt.Printf("Trying to move robot in position %d in direction %d. ", r.position, dir) }
-  boxesToMove := make(map[int]struct{})
-  couldMove := canMove([]int{r.position}, w, dir, r, &boxesToMove)
-  if isDebug { fmt.Printf("Can move? %v\n", couldMove) }
+func movePart2(w *Warehouse2, dir grid.Direction, r *Robot) bool {
+  if traceMoves.On() { traceMoves.Printf("Trying to move robot in position %v %v. ", r.position, dir) }
+  boxesToMove := make(map[grid.Coord]struct{})
+  couldMove := canMove([]grid.Coord{r.position}, w, dir, r, &boxesToMove)
+  if traceMoves.On() { traceMoves.Printf("Can move? %v\n", couldMove) }
   if couldMove {
-    if isDebug {
-      fmt.Printf("Boxes to move: %+v\n", boxesToMove)
-      fmt.Printf("Boxes before moving: %+v\n", w.boxes)
+    if traceMoves.On() {
+      traceMoves.Printf("Boxes to move: %+v\n", boxesToMove)
+      traceMoves.Printf("Boxes before moving: %+v\n", w.boxes)
     }
-    boxPositions := make([]int, len(boxesToMove))
-    i := 0
+    boxPositions := make([]grid.Coord, 0, len(boxesToMove))
     for pos := range boxesToMove {
-      boxPositions[i] = pos
-      i++
-    }
-    if dir == Up || dir == Left {
-      sort.Sort(sort.IntSlice(boxPositions))
-    } else {
-      sort.Sort(sort.Reverse(sort.IntSlice(boxPositions)))
+      boxPositions = append(boxPositions, pos)
     }
+    // Boxes furthest along the move go first, so no box is moved onto one that hasn't moved yet
+    slices.SortFunc(boxPositions, func(a, b grid.Coord) int { return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X)) })
+    if dir == grid.Down || dir == grid.Right { slices.Reverse(boxPositions) }
     for _, pos := range boxPositions {
       b, _ := w.boxes[pos]
-      if isDebug { fmt.Printf("Moving box at position %d (start %d, end %d)\n", pos, b.start, b.end) }
+      if traceMoves.On() { traceMoves.Printf("Moving box at position %v (start %v, end %v)\n", pos, b.start, b.end) }
       delete(w.boxes, b.start)
       delete(w.boxes, b.end)
-      if isDebug { fmt.Printf("Deleted box from map: %+v\n", w.boxes) }
-      if dir == Up {
-        b.start = b.start - w.width
-        b.end = b.end - w.width
-      } else if dir == Down {
-        b.start = b.start + w.width
-        b.end = b.end + w.width
-      } else if dir == Left {
-        b.start = b.start - 1
-        b.end = b.end - 1
-      } else {
-        b.start = b.start + 1
-        b.end = b.end + 1
-      }
+      if traceMoves.On() { traceMoves.Printf("Deleted box from map: %+v\n", w.boxes) }
+      b.start = b.start.Step(dir)
+      b.end = b.end.Step(dir)
       w.boxes[b.start] = b
       w.boxes[b.end] = b
-      if isDebug { fmt.Printf("Added new box references to map: %+v\n", w.boxes) }
+      if traceMoves.On() { traceMoves.Printf("Added new box references to map: %+v\n", w.boxes) }
     }
-    if isDebug { fmt.Printf("Boxes after moving: %+v\n", w.boxes) }
-    if dir == Up {
-      r.position = r.position - w.width
-    } else if dir == Down {
-      r.position = r.position + w.width
-    } else if dir == Left {
-      r.position = r.position - 1
-    } else {
-      r.position = r.position + 1
-    }
-    if isDebug { fmt.Printf("Robot is now at position %d\n", r.position) }
+    if traceMoves.On() { traceMoves.Printf("Boxes after moving: %+v\n", w.boxes) }
+    r.position = r.position.Step(dir)
+    if traceMoves.On() { traceMoves.Printf("Robot is now at position %v\n", r.position) }
   }
   return couldMove
 }
@@ -255,9 +215,7 @@ func solve(warehouse *Warehouse, robot *Robot) int {
     move(robot.position, warehouse, direction, robot)
   }
   for box := range warehouse.boxes {
-    x := box % warehouse.width
-    y := box / warehouse.width
-    sum += 100 * y + x
+    sum += 100 * box.Y + box.X
   }
   return sum
 }
@@ -265,75 +223,64 @@ func solve(warehouse *Warehouse, robot *Robot) int {
 func solvePart2(warehouse *Warehouse2, robot *Robot) int {
   sum := 0
   for i, direction := range robot.moves {
-    if isAnimated {
+    if traceAnimation.On() {
       renderAnimated(i, direction, warehouse, robot)
     }
     movePart2(warehouse, direction, robot)
-    if isDebug { renderPart2(warehouse, robot) }
+    if traceMoves.On() { renderPart2(warehouse, robot) }
   }
-  boxes := make(map[int]struct{})
-  for box := range warehouse.boxes {
-    boxes[warehouse.boxes[box].start] = struct{}{}
+  starts := make(map[grid.Coord]struct{})
+  for _, box := range warehouse.boxes {
+    starts[box.start] = struct{}{}
   }
-  for box := range boxes {
-    x := box % warehouse.width
-    y := box / warehouse.width
-    sum += 100 * y + x
+  for pos := range starts {
+    sum += 100 * pos.Y + pos.X
   }
   return sum
 }
 
 func clearScr() {
   c := exec.Command("clear")
-  c.Stdout = os.Stdout
+  c.Stdout = trace.Output()
   c.Run()
 }
 
-func renderAnimated(frame int, dir Direction, warehouse *Warehouse2, robot *Robot) {
+func renderAnimated(frame int, dir grid.Direction, warehouse *Warehouse2, robot *Robot) {
   clearScr()
-  heading := '^'
-  if dir == Right {
-    heading = '>'
-  } else if dir == Down {
-    heading = 'v'
-  } else if dir == Left {
-    heading = '<'
-  }
-  fmt.Printf("\n\nFrame: %d || Robot moving %c || Moves pending: %d\n\n", frame + 1, heading, len(robot.moves) - frame)
-  i := 0
-  for range warehouse.height {
-    for range warehouse.width {
+  traceAnimation.Printf("\n\nFrame: %d || Robot moving %c || Moves pending: %d\n\n", frame + 1, moveChars[dir], len(robot.moves) - frame)
+  for y := range warehouse.height {
+    for x := range warehouse.width {
+      i := grid.Coord{X: x, Y: y}
       _, isWall := warehouse.walls[i]
       box, isBox := warehouse.boxes[i]
       if isWall {
-        fmt.Print("▓")
+        traceAnimation.Print("▓")
       } else if isBox {
         if box.start == i {
-          fmt.Print("\033[34m[")
+          traceAnimation.Print("\033[34m[")
         } else {
-          fmt.Print("]\033[0m")
+          traceAnimation.Print("]\033[0m")
         }
       } else if i == robot.position {
-        fmt.Print("§")
+        traceAnimation.Print("§")
       } else {
-        fmt.Print("\033[32m░\033[0m")
+        traceAnimation.Print("\033[32m░\033[0m")
       }
-      i++
     }
-    fmt.Print("\n")
+    traceAnimation.Print("\n")
   }
-  fmt.Print("\n\n")
+  traceAnimation.Print("\n\n")
   //time.Sleep(30 * time.Millisecond)
 }
 
 func parseInput(lines []string) (*Warehouse, *Robot) {
-  w := Warehouse{len(lines[0]), 0, make(map[int]struct{}), make(map[int]struct{})}
+  w := Warehouse{len(lines[0]), 0, make(map[grid.Coord]struct{}), make(map[grid.Coord]struct{})}
   for y, line := range lines {
     if len(line) == 0 {
       w.height = y
     }
   }
-  r := Robot{0, make([]Direction, 0)}
+  r := Robot{grid.Coord{}, make([]grid.Direction, 0)}
   parseWarehouse := true
   for y, line := range lines {
     if len(line) == 0 {
@@ -341,7 +288,7 @@ func parseInput(lines []string) (*Warehouse, *Robot) {
     }
     if parseWarehouse {
       for x, char := range line {
-        i := y * w.width + x
+        i := grid.Coord{X: x, Y: y}
         if char == '.' { continue }
         if char == '#' {
           w.walls[i] = struct{}{}
@@ -354,15 +301,7 @@ func parseInput(lines []string) (*Warehouse, *Robot) {
       continue
     }
     for _, char := range line {
-      toAppend := Up
-      if char == '>' {
-        toAppend = Right
-      } else if char == 'v' {
-        toAppend = Down
-      } else if char == '<'{
-        toAppend = Left
-      }
-      r.moves = append(r.moves, toAppend)
+      r.moves = append(r.moves, grid.Direction(strings.IndexRune(moveChars, char)))
     }
   }
   return &w, &r
@@ -389,15 +328,14 @@ func convertInputToPart2(lines *[]string) {
 
 func parseInputPart2(lines []string) (*Warehouse2, *Robot) {
   convertInputToPart2(&lines)
-  if isDebug && useDebugData { lines = generateDebugData(true) }
-  w := Warehouse2{len(lines[0]), 0, make(map[int]*Box), make(map[int]struct{})}
+  w := Warehouse2{len(lines[0]), 0, make(map[grid.Coord]*Box), make(map[grid.Coord]struct{})}
   for y, line := range lines {
     if len(line) == 0 {
       w.height = y
       break
     }
   }
-  r := Robot{0, make([]Direction, 0)}
+  r := Robot{grid.Coord{}, make([]grid.Direction, 0)}
   parseWarehouse := true
   for y, line := range lines {
     if len(line) == 0 {
@@ -405,14 +343,14 @@ func parseInputPart2(lines []string) (*Warehouse2, *Robot) {
     }
     if parseWarehouse {
       for x, char := range line {
-        i := y * len(line) + x
+        i := grid.Coord{X: x, Y: y}
         if char == '.' || char == ']' { continue }
         if char == '#' {
           w.walls[i] = struct{}{}
         } else if char == '[' {
-          box := Box{i, i + 1}
-          w.boxes[i] = &box
-          w.boxes[i + 1] = &box
+          box := Box{i, i.Step(grid.Right)}
+          w.boxes[box.start] = &box
+          w.boxes[box.end] = &box
         } else {
           r.position = i
         }
@@ -420,63 +358,55 @@ func parseInputPart2(lines []string) (*Warehouse2, *Robot) {
       continue
     }
     for _, char := range line {
-      toAppend := Up
-      if char == '>' {
-        toAppend = Right
-      } else if char == 'v' {
-        toAppend = Down
-      } else if char == '<'{
-        toAppend = Left
-      }
-      r.moves = append(r.moves, toAppend)
+      r.moves = append(r.moves, grid.Direction(strings.IndexRune(moveChars, char)))
     }
   }
   return &w, &r
 }
 
 func render(w *Warehouse, r *Robot) {
-  fmt.Print("\n\n")
+  traceMoves.Print("\n\n")
   for y := range w.height {
     for x := range w.width {
-      i := y * w.width + x
+      i := grid.Coord{X: x, Y: y}
       _, isWall := w.walls[i]
       _, isBox := w.boxes[i]
       isRobot := i == r.position
       if isWall {
-        fmt.Print("#")
+        traceMoves.Print("#")
       } else if isBox {
-        fmt.Print("O")
+        traceMoves.Print("O")
       } else if isRobot {
-        fmt.Print("@")
+        traceMoves.Print("@")
       } else {
-        fmt.Print(".")
+        traceMoves.Print(".")
       }
     }
-    fmt.Print("\n")
+    traceMoves.Print("\n")
   }
-  fmt.Print("\n")
+  traceMoves.Print("\n")
 }
 
 func renderPart2(w *Warehouse2, r *Robot) {
-  fmt.Print("\n\n")
+  traceMoves.Print("\n\n")
   for y := range w.height {
     for x := 0; x < w.width; x++ {
-      i := y * w.width + x
+      i := grid.Coord{X: x, Y: y}
       _, isWall := w.walls[i]
       box, isBox := w.boxes[i]; if isBox { isBox = box.start == i }
       isRobot := i == r.position
       if isWall {
-        fmt.Print("#")
+        traceMoves.Print("#")
       } else if isBox {
-        fmt.Print("[]")
+        traceMoves.Print("[]")
         x++
       } else if isRobot {
-        fmt.Print("@")
+        traceMoves.Print("@")
       } else {
-        fmt.Print(".")
+        traceMoves.Print(".")
       }
     }
-    fmt.Print("\n")
+    traceMoves.Print("\n")
   }
-  fmt.Print("\n")
+  traceMoves.Print("\n")
 }
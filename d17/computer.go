@@ -0,0 +1,133 @@
+package d17
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A program of 3 bit values, opcodes and operands taking turns
+type Program []uint8
+
+type Registers struct {
+  A, B, C int
+}
+
+// Pointer to the register with the given name, or nil if there's no such register
+func (r *Registers) named(name rune) *int {
+  switch name {
+  case 'A':
+    return &r.A
+  case 'B':
+    return &r.B
+  case 'C':
+    return &r.C
+  }
+  return nil
+}
+
+type Output []uint8
+
+// Renders the output the way the puzzle expects it, i.e. values separated by commas
+func (o Output) String() string {
+  values := make([]string, len(o))
+  for i, value := range o {
+    values[i] = strconv.Itoa(int(value))
+  }
+  return strings.Join(values, ",")
+}
+
+// The Chronospatial computer. All of its state is its own, so any number of them can run at once
+type Computer struct {
+  Registers Registers
+  // Index of the next opcode in the program
+  Pointer int
+  Output Output
+}
+
+type InstFn func(*Computer, uint8)
+
+var instructions = map[uint8]InstFn {
+  // adv - 0 = division between A reg and 2^combo
+  0: func(c *Computer, combo uint8) {
+    c.Registers.A /= pow2(c.comboVal(combo))
+  },
+  // bxl - 1 = bitwise XOR of B reg and literal
+  1: func(c *Computer, literal uint8) {
+    c.Registers.B ^= int(literal)
+  },
+  // bst - 2 = combo operand modulo 8
+  2: func(c *Computer, combo uint8) {
+    c.Registers.B = c.comboVal(combo) % 8
+  },
+  // jnz - 3 = jump to literal index in program if register A > 0
+  3: func(c *Computer, literal uint8) {
+    if c.Registers.A > 0 {
+      // Subtract 2 from literal to cancel out the +2 applied to pointer at the end of each cycle
+      c.Pointer = int(literal) - 2
+    }
+  },
+  // bxc - 4 = bitwise XOR of B reg and C reg
+  4: func(c *Computer, _ uint8) {
+    c.Registers.B ^= c.Registers.C
+  },
+  // out - 5 = output combo modulo 8
+  5: func(c *Computer, combo uint8) {
+    c.Output = append(c.Output, uint8(c.comboVal(combo) % 8))
+  },
+  // bdv - 6 = exactly like 0 but storing result in B reg
+  6: func(c *Computer, combo uint8) {
+    c.Registers.B = c.Registers.A / pow2(c.comboVal(combo))
+  },
+  // cdv - 7 = exactly like 0 but storing result in C reg
+  7: func(c *Computer, combo uint8) {
+    c.Registers.C = c.Registers.A / pow2(c.comboVal(combo))
+  },
+}
+
+func NewComputer(registers Registers) *Computer {
+  return &Computer{Registers: registers, Output: Output{}}
+}
+
+// Whether the pointer went past the program, which halts it
+func (c *Computer) Halted(program Program) bool {
+  return c.Pointer >= len(program) - 1
+}
+
+// Runs a single instruction, unless the program already halted. Returns whether it ran one
+func (c *Computer) Step(program Program) bool {
+  if c.Halted(program) { return false }
+  instructions[program[c.Pointer]](c, program[c.Pointer + 1])
+  c.Pointer += 2
+  return true
+}
+
+// Instructions Run takes at most. The puzzle inputs halt after a few hundred, but a program can also jump back to
+// itself forever
+const runLimit = 1 << 22
+
+// Runs the program from where the pointer is until it halts, or for at most runLimit instructions, and returns
+// everything it output. Halted tells whether it got to the end
+func (c *Computer) Run(program Program) Output {
+  for steps := 0; steps < runLimit && c.Step(program); steps++ {}
+  return c.Output
+}
+
+func (c *Computer) comboVal(combo uint8) int {
+  switch combo {
+  case 4:
+    return c.Registers.A
+  case 5:
+    return c.Registers.B
+  case 6:
+    return c.Registers.C
+  case 7:
+    panic("Illegal use of reserved combo operand 7! Program is invalid")
+  default:
+    return int(combo)
+  }
+}
+
+func pow2(x int) int {
+  return int(math.Pow(2, float64(x)))
+}
@@ -0,0 +1,60 @@
+package d17_test
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/d17"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"errors"
+	"testing"
+)
+
+// The small examples of the puzzle, each checking the output or a register once the program halts
+func TestComputer(t *testing.T) {
+  examples := []struct {
+    registers d17.Registers
+    program d17.Program
+    output string
+    expected d17.Registers
+  }{
+    {d17.Registers{C: 9}, d17.Program{2, 6}, "", d17.Registers{B: 1, C: 9}},
+    {d17.Registers{A: 10}, d17.Program{5, 0, 5, 1, 5, 4}, "0,1,2", d17.Registers{A: 10}},
+    {d17.Registers{A: 2024}, d17.Program{0, 1, 5, 4, 3, 0}, "4,2,5,6,7,7,7,7,3,1,0", d17.Registers{}},
+    {d17.Registers{B: 29}, d17.Program{1, 7}, "", d17.Registers{B: 26}},
+    {d17.Registers{B: 2024, C: 43690}, d17.Program{4, 0}, "", d17.Registers{B: 44354, C: 43690}},
+  }
+  for _, example := range examples {
+    computer := d17.NewComputer(example.registers)
+    output := computer.Run(example.program)
+    if output.String() != example.output { t.Errorf("%v: output %s, expected %s", example.program, output, example.output) }
+    if computer.Registers != example.expected { t.Errorf("%v: registers %+v, expected %+v", example.program, computer.Registers, example.expected) }
+  }
+}
+
+func TestComputerStep(t *testing.T) {
+  program := d17.Program{0, 1, 5, 4, 3, 0}
+  computer := d17.NewComputer(d17.Registers{A: 4})
+  // A goes 4, 2, 1 then 0, each pass running adv, out and jnz, which falls through once A is 0
+  steps := 0
+  for computer.Step(program) {
+    steps++
+  }
+  if steps != 9 || !computer.Halted(program) { t.Errorf("halted after %d steps, expected 9", steps) }
+  if computer.Output.String() != "2,1,0" { t.Errorf("output %s, expected 2,1,0", computer.Output) }
+  if computer.Step(program) { t.Error("stepped past the end of the program") }
+}
+
+// Jumping back to the start while A isn't 0, without ever changing it, loops forever
+func TestComputerRunLimit(t *testing.T) {
+  program := d17.Program{0, 0, 3, 0}
+  computer := d17.NewComputer(d17.Registers{A: 10})
+  computer.Run(program)
+  if computer.Halted(program) { t.Error("halted a program that loops forever") }
+
+  entry, _ := registry.Get(constants.Seventeen)
+  solver := entry.New()
+  err := solver.Parse([]string{"Register A: 10", "Register B: 0", "Register C: 0", "", "Program: 0,0,3,0"})
+  if err != nil { t.Fatal(err) }
+  _, err = solver.Part1()
+  if !errors.Is(err, errs.ErrPartUnsolvable) { t.Errorf("got %v, expected part 1 to be unsolvable", err) }
+}
@@ -0,0 +1,29 @@
+package d17_test
+
+import (
+	"aoc2k24/d17"
+	"aoc2k24/errs"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseInput(t *testing.T) {
+  registers, program, err := d17.ParseInput([]string{"Register A: 729", "Register B: 0", "Register C: 0", "", "Program: 0,1,5,4,3,0"})
+  if err != nil { t.Fatal(err) }
+  if registers != (d17.Registers{A: 729}) { t.Errorf("got registers %+v", registers) }
+  if !slices.Equal(program, d17.Program{0, 1, 5, 4, 3, 0}) { t.Errorf("got program %v", program) }
+
+  // Missing or negative registers would make adv, bdv and cdv divide by 0 rather than fail here
+  malformed := [][]string{
+    {"Register A: 729", "Register C: 0", "", "Program: 0,1,5,4,3,0"},
+    {"Program: 0,1,5,4,3,0"},
+    {"Register A: -1", "Register B: 0", "Register C: 0", "", "Program: 0,4,5,4,3,0"},
+    {"Register A: 729", "Register B: 0", "Register C: 0", "", "Program: 0,1,5,8"},
+    {"Register A: 729", "Register B: 0", "Register C: 0", "", "Program: 0,1,5"},
+  }
+  for _, lines := range malformed {
+    _, _, err := d17.ParseInput(lines)
+    if !errors.Is(err, errs.ErrMalformedInput) { t.Errorf("%q: got %v, expected a malformed input", lines, err) }
+  }
+}
@@ -0,0 +1,225 @@
+package d17
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const debuggerHelp = `Commands:
+  step [n], s [n]     run the next n instructions, 1 by default
+  continue, c         run until a breakpoint is reached or the program halts, giving up on programs that
+                      loop forever
+  break <address>     stop before running the instruction at the address
+  break out <n>       stop once the output has n values
+  delete              remove every breakpoint
+  watch [A] [B] [C]   print the given registers, or all of them, after every step and stop
+  unwatch             stop printing registers
+  regs, r             print the registers
+  output, o           print the output so far
+  set <reg> <value>   change a register
+  reset [A]           start over from the initial registers, optionally with another value of A
+  list, l             disassemble the program, marking the next instruction
+  help, h             print this help
+  quit, q             leave the debugger
+Empty lines and lines starting with # are skipped, so scripts can be commented
+`
+
+// Steps a computer through a program from commands, one per line. It only deals with a reader and a writer, so a
+// script of commands drives it just as well as someone typing them
+type Debugger struct {
+  Computer *Computer
+  Program Program
+  // Printed before reading every command. Left empty for scripts, so only results get written
+  Prompt string
+  initial Registers
+  labels map[int]string
+  breakpoints map[int]bool
+  // Stops once the output has this many values, 0 if it shouldn't
+  outputBreak int
+  watched []rune
+  out io.Writer
+}
+
+func NewDebugger(registers Registers, program Program, out io.Writer) *Debugger {
+  return &Debugger{
+    Computer: NewComputer(registers),
+    Program: program,
+    initial: registers,
+    labels: Labels(program),
+    breakpoints: map[int]bool{},
+    out: out,
+  }
+}
+
+// Runs every command read from in, until quit or the end of in. Failing commands are reported and the next ones still
+// run
+func (d *Debugger) Run(in io.Reader) error {
+  scanner := bufio.NewScanner(in)
+  for {
+    fmt.Fprint(d.out, d.Prompt)
+    if !scanner.Scan() { break }
+    line := strings.TrimSpace(scanner.Text())
+    if line == "" || strings.HasPrefix(line, "#") { continue }
+    isQuit, err := d.Execute(line)
+    if err != nil { fmt.Fprintf(d.out, "Error: %v\n", err) }
+    if isQuit { return nil }
+  }
+  return scanner.Err()
+}
+
+// Runs a single command, returning whether it was quit
+func (d *Debugger) Execute(command string) (bool, error) {
+  args := strings.Fields(command)
+  if len(args) == 0 { return false, nil }
+  switch name := args[0]; name {
+  case "step", "s":
+    count := 1
+    if len(args) > 1 {
+      var err error
+      count, err = strconv.Atoi(args[1])
+      if err != nil || count < 1 { return false, fmt.Errorf("invalid step count %q", args[1]) }
+    }
+    d.step(count)
+  case "continue", "c":
+    d.proceed()
+  case "break", "b":
+    return false, d.addBreakpoint(args[1:])
+  case "delete":
+    d.breakpoints = map[int]bool{}
+    d.outputBreak = 0
+  case "watch":
+    return false, d.watch(args[1:])
+  case "unwatch":
+    d.watched = nil
+  case "regs", "r":
+    d.printRegisters([]rune{'A', 'B', 'C'})
+  case "output", "o":
+    fmt.Fprintf(d.out, "Output: %s\n", d.Computer.Output)
+  case "set":
+    if len(args) != 3 { return false, fmt.Errorf("expected set <register> <value>") }
+    register, err := d.register(args[1])
+    if err != nil { return false, err }
+    value, err := registerValue(args[2])
+    if err != nil { return false, err }
+    *register = value
+  case "reset":
+    registers := d.initial
+    if len(args) > 1 {
+      var err error
+      registers.A, err = registerValue(args[1])
+      if err != nil { return false, err }
+    }
+    d.Computer = NewComputer(registers)
+  case "list", "l":
+    fmt.Fprint(d.out, listing(d.Program, d.Computer.Pointer))
+  case "help", "h":
+    fmt.Fprint(d.out, debuggerHelp)
+  case "quit", "q":
+    return true, nil
+  default:
+    return false, fmt.Errorf("unknown command %q, help lists them", name)
+  }
+  return false, nil
+}
+
+func (d *Debugger) step(count int) {
+  for range count {
+    if d.Computer.Halted(d.Program) {
+      d.printHalted()
+      return
+    }
+    instruction := d.next()
+    d.Computer.Step(d.Program)
+    fmt.Fprintf(d.out, "%02d  %s\n", instruction.Address, instruction.Text(d.labels))
+    d.printRegisters(d.watched)
+  }
+}
+
+// Runs until the next stop. It always runs at least one instruction, so continuing from a breakpoint gets past it. Like
+// Computer.Run, it gives up after runLimit instructions, for programs that loop forever
+func (d *Debugger) proceed() {
+  for steps := 0; !d.Computer.Halted(d.Program); steps++ {
+    if steps == runLimit {
+      instruction := d.next()
+      fmt.Fprintf(d.out, "Stopped before %02d  %s: still running after %d instructions\n", instruction.Address, instruction.Text(d.labels), runLimit)
+      d.printRegisters(d.watched)
+      return
+    }
+    outputLength := len(d.Computer.Output)
+    d.Computer.Step(d.Program)
+    reason := ""
+    if d.outputBreak > 0 && outputLength < d.outputBreak && len(d.Computer.Output) >= d.outputBreak {
+      reason = fmt.Sprintf("output has %d values", len(d.Computer.Output))
+    }
+    if d.breakpoints[d.Computer.Pointer] { reason = "breakpoint" }
+    if reason == "" || d.Computer.Halted(d.Program) { continue }
+    instruction := d.next()
+    fmt.Fprintf(d.out, "Stopped before %02d  %s: %s\n", instruction.Address, instruction.Text(d.labels), reason)
+    d.printRegisters(d.watched)
+    return
+  }
+  d.printHalted()
+}
+
+func (d *Debugger) addBreakpoint(args []string) error {
+  if len(args) == 2 && args[0] == "out" {
+    count, err := strconv.Atoi(args[1])
+    if err != nil || count < 1 { return fmt.Errorf("invalid output length %q", args[1]) }
+    d.outputBreak = count
+    return nil
+  }
+  if len(args) != 1 { return fmt.Errorf("expected break <address> or break out <n>") }
+  address, err := strconv.Atoi(args[0])
+  if err != nil || address < 0 || address >= len(d.Program) { return fmt.Errorf("invalid address %q", args[0]) }
+  d.breakpoints[address] = true
+  return nil
+}
+
+func (d *Debugger) watch(names []string) error {
+  if len(names) == 0 { names = []string{"A", "B", "C"} }
+  for _, name := range names {
+    _, err := d.register(name)
+    if err != nil { return err }
+    if !slices.Contains(d.watched, rune(name[0])) { d.watched = append(d.watched, rune(name[0])) }
+  }
+  slices.Sort(d.watched)
+  return nil
+}
+
+// Registers can't be negative, as ParseInput also checks: the divisions would shift by a power of 2 that is then 0
+func registerValue(arg string) (int, error) {
+  value, err := strconv.Atoi(arg)
+  if err != nil || value < 0 { return 0, fmt.Errorf("invalid value %q, registers hold numbers from 0 up", arg) }
+  return value, nil
+}
+
+func (d *Debugger) register(name string) (*int, error) {
+  register := (*int)(nil)
+  if len(name) == 1 { register = d.Computer.Registers.named(rune(name[0])) }
+  if register == nil { return nil, fmt.Errorf("unknown register %q, expected A, B or C", name) }
+  return register, nil
+}
+
+// The instruction at the pointer, which must not have halted
+func (d *Debugger) next() Instruction {
+  pointer := d.Computer.Pointer
+  return Instruction{pointer, d.Program[pointer], d.Program[pointer + 1]}
+}
+
+func (d *Debugger) printRegisters(names []rune) {
+  if len(names) == 0 { return }
+  values := make([]string, len(names))
+  for i, name := range names {
+    values[i] = fmt.Sprintf("%c=%d", name, *d.Computer.Registers.named(name))
+  }
+  fmt.Fprintln(d.out, strings.Join(values, " "))
+}
+
+func (d *Debugger) printHalted() {
+  fmt.Fprintf(d.out, "Halted, output: %s\n", d.Computer.Output)
+  d.printRegisters(d.watched)
+}
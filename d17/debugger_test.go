@@ -0,0 +1,97 @@
+package d17_test
+
+import (
+	"aoc2k24/d17"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Runs the script on the example of part 1 and returns everything the debugger wrote
+func debug(t *testing.T, script string) string {
+  t.Helper()
+  var out bytes.Buffer
+  debugger := d17.NewDebugger(d17.Registers{A: 729}, d17.Program{0, 1, 5, 4, 3, 0}, &out)
+  err := debugger.Run(strings.NewReader(script))
+  if err != nil { t.Fatal(err) }
+  return out.String()
+}
+
+func TestDebuggerStep(t *testing.T) {
+  script := `
+    # Steps over adv and out, watching A
+    watch A
+    step 2
+    output
+  `
+  expected := "00  adv 1\nA=364\n02  out A\nA=364\nOutput: 4\n"
+  out := debug(t, script)
+  if out != expected { t.Errorf("got\n%s\nexpected\n%s", out, expected) }
+}
+
+func TestDebuggerBreakpoints(t *testing.T) {
+  script := `
+    break 2
+    continue
+    continue
+    delete
+    break out 4
+    continue
+    delete
+    continue
+    regs
+  `
+  expected := strings.Join([]string{
+    "Stopped before 02  out A: breakpoint",
+    // Continuing from a breakpoint gets past it, up to the next time round the loop
+    "Stopped before 02  out A: breakpoint",
+    "Stopped before 04  jnz L0: output has 4 values",
+    "Halted, output: 4,6,3,5,6,3,5,2,1,0",
+    "A=0 B=0 C=0",
+  }, "\n") + "\n"
+  out := debug(t, script)
+  if out != expected { t.Errorf("got\n%s\nexpected\n%s", out, expected) }
+}
+
+func TestDebuggerCommands(t *testing.T) {
+  script := `
+    set B 5
+    reset 8
+    regs
+    step 3
+    list
+    frobnicate
+    break 9
+    quit
+    regs
+  `
+  expected := strings.Join([]string{
+    // Resetting goes back to the initial registers, so B is 0 again
+    "A=8 B=0 C=0",
+    "00  adv 1",
+    "02  out A",
+    "04  jnz L0",
+    "L0:",
+    "=>00  adv 1",
+    "  02  out A",
+    "  04  jnz L0",
+    `Error: unknown command "frobnicate", help lists them`,
+    `Error: invalid address "9"`,
+  }, "\n") + "\n"
+  out := debug(t, script)
+  if out != expected { t.Errorf("got\n%s\nexpected\n%s", out, expected) }
+}
+
+// Negative registers would make the divisions divide by 0, and a program jumping back to itself never halts
+func TestDebuggerLimits(t *testing.T) {
+  var out bytes.Buffer
+  debugger := d17.NewDebugger(d17.Registers{A: 10}, d17.Program{0, 0, 3, 0}, &out)
+  err := debugger.Run(strings.NewReader("set A -9\nreset -1\ncontinue\n"))
+  if err != nil { t.Fatal(err) }
+  expected := strings.Join([]string{
+    `Error: invalid value "-9", registers hold numbers from 0 up`,
+    `Error: invalid value "-1", registers hold numbers from 0 up`,
+    "Stopped before 00  adv 0: still running after 4194304 instructions",
+  }, "\n") + "\n"
+  if out.String() != expected { t.Errorf("got\n%s\nexpected\n%s", out.String(), expected) }
+}
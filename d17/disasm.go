@@ -0,0 +1,146 @@
+package d17
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var mnemonics = [8]string{"adv", "bxl", "bst", "jnz", "bxc", "out", "bdv", "cdv"}
+
+// An opcode and its operand, at the address of the opcode
+type Instruction struct {
+  Address int
+  Opcode uint8
+  Operand uint8
+}
+
+// Splits the program into its instructions. A trailing opcode without operand is left out
+func Instructions(program Program) []Instruction {
+  instructions := make([]Instruction, 0, len(program) / 2)
+  for address := 0; address < len(program) - 1; address += 2 {
+    instructions = append(instructions, Instruction{address, program[address], program[address + 1]})
+  }
+  return instructions
+}
+
+func (i Instruction) Mnemonic() string {
+  return mnemonics[i.Opcode]
+}
+
+// Whether the operand is a combo one, i.e. stands for a register from 4 up, rather than a literal
+func (i Instruction) UsesCombo() bool {
+  return i.Opcode == 0 || i.Opcode == 2 || i.Opcode == 5 || i.Opcode == 6 || i.Opcode == 7
+}
+
+// The operand as it's used: the register a combo operand stands for, or its value. bxc ignores its operand, so it has
+// none
+func (i Instruction) OperandText() string {
+  switch {
+  case i.Opcode == 4:
+    return ""
+  case i.UsesCombo() && i.Operand >= 4 && i.Operand <= 6:
+    return string(rune('A' + i.Operand - 4))
+  case i.UsesCombo() && i.Operand == 7:
+    return "<reserved>"
+  }
+  return strconv.Itoa(int(i.Operand))
+}
+
+// The instruction as a statement, e.g. B = A & 7 for bst A
+func (i Instruction) Statement(labels map[int]string) string {
+  operand := i.OperandText()
+  switch i.Opcode {
+  case 0:
+    return "A = A >> " + operand
+  case 1:
+    return "B = B ^ " + operand
+  case 2:
+    return fmt.Sprintf("B = %s & 7", operand)
+  case 3:
+    return "if A > 0 goto " + target(i.Operand, labels)
+  case 4:
+    return "B = B ^ C"
+  case 5:
+    return fmt.Sprintf("out(%s & 7)", operand)
+  case 6:
+    return "B = A >> " + operand
+  default:
+    return "C = A >> " + operand
+  }
+}
+
+// Labels of the addresses jumped to, L0, L1 and so on in address order. Only those of instructions get one, since a
+// jump anywhere else either lands in the middle of an instruction or halts
+func Labels(program Program) map[int]string {
+  isTarget := make([]bool, len(program))
+  for _, instruction := range Instructions(program) {
+    target := int(instruction.Operand)
+    if instruction.Opcode == 3 && target % 2 == 0 && target < len(program) - 1 { isTarget[target] = true }
+  }
+  labels := map[int]string{}
+  for address, isLabelled := range isTarget {
+    if isLabelled { labels[address] = fmt.Sprintf("L%d", len(labels)) }
+  }
+  return labels
+}
+
+func target(address uint8, labels map[int]string) string {
+  label, isLabelled := labels[int(address)]
+  if isLabelled { return label }
+  return strconv.Itoa(int(address))
+}
+
+// The mnemonic and its decoded operand, with the label of the target for jumps
+func (i Instruction) Text(labels map[int]string) string {
+  operand := i.OperandText()
+  if i.Opcode == 3 { operand = target(i.Operand, labels) }
+  return strings.TrimSpace(i.Mnemonic() + " " + operand)
+}
+
+// One line per instruction with its address, mnemonic and decoded operand, preceded by the label of jump targets
+func Disassemble(program Program) string {
+  return listing(program, -1)
+}
+
+// The listing of Disassemble, with the instruction at the pointer marked
+func listing(program Program, pointer int) string {
+  labels := Labels(program)
+  var listing strings.Builder
+  for _, instruction := range Instructions(program) {
+    label, isLabelled := labels[instruction.Address]
+    if isLabelled { fmt.Fprintf(&listing, "%s:\n", label) }
+    marker := "  "
+    if instruction.Address == pointer { marker = "=>" }
+    fmt.Fprintf(&listing, "%s%02d  %s\n", marker, instruction.Address, instruction.Text(labels))
+  }
+  return listing.String()
+}
+
+// The program as pseudo-code. The usual shape, a loop back to the start that only jumps at its end, becomes a
+// do while loop. Anything else keeps its labels and gotos
+func Pseudocode(program Program) string {
+  instructions := Instructions(program)
+  labels := Labels(program)
+  var code strings.Builder
+  jumps := 0
+  for _, instruction := range instructions {
+    if instruction.Opcode == 3 { jumps++ }
+  }
+  last := len(instructions) - 1
+  isLoop := jumps == 1 && last >= 0 && instructions[last].Opcode == 3 && instructions[last].Operand == 0
+  if isLoop {
+    code.WriteString("do {\n")
+    for _, instruction := range instructions[:last] {
+      fmt.Fprintf(&code, "  %s\n", instruction.Statement(labels))
+    }
+    code.WriteString("} while A > 0\n")
+    return code.String()
+  }
+  for _, instruction := range instructions {
+    label, isLabelled := labels[instruction.Address]
+    if isLabelled { fmt.Fprintf(&code, "%s:\n", label) }
+    fmt.Fprintf(&code, "  %s\n", instruction.Statement(labels))
+  }
+  return code.String()
+}
@@ -0,0 +1,30 @@
+package d17_test
+
+import (
+	"aoc2k24/d17"
+	"testing"
+)
+
+// The quine example of part 2
+var quine = d17.Program{0, 3, 5, 4, 3, 0}
+
+func TestDisassemble(t *testing.T) {
+  expected := "L0:\n  00  adv 3\n  02  out A\n  04  jnz L0\n"
+  listing := d17.Disassemble(quine)
+  if listing != expected { t.Errorf("got\n%s\nexpected\n%s", listing, expected) }
+
+  // A register operand is decoded, bxc has none, and a jump that isn't to an instruction keeps its address
+  listing = d17.Disassemble(d17.Program{7, 5, 4, 1, 3, 3})
+  expected = "  00  cdv B\n  02  bxc\n  04  jnz 3\n"
+  if listing != expected { t.Errorf("got\n%s\nexpected\n%s", listing, expected) }
+}
+
+func TestPseudocode(t *testing.T) {
+  expected := "do {\n  A = A >> 3\n  out(A & 7)\n} while A > 0\n"
+  code := d17.Pseudocode(quine)
+  if code != expected { t.Errorf("got\n%s\nexpected\n%s", code, expected) }
+
+  expected = "L0:\n  B = 2 & 7\n  if A > 0 goto L0\n  out(B & 7)\n"
+  code = d17.Pseudocode(d17.Program{2, 2, 3, 0, 5, 5})
+  if code != expected { t.Errorf("got\n%s\nexpected\n%s", code, expected) }
+}
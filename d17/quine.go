@@ -0,0 +1,84 @@
+package d17
+
+import (
+	"slices"
+)
+
+// Values of register A tried by the bounded check, for programs the backward search doesn't apply to
+const checkLimit = 1 << 20
+
+// Instructions a single run of the search may take, so that programs looping forever without output still end
+const maxSteps = 1 << 16
+
+// How many bits of A every loop of the program consumes, when it has the usual shape: a single loop shifting A right
+// by a constant. 0 if it doesn't have that shape
+func loopShift(program Program) int {
+  if !isSingleLoop(program) { return 0 }
+  shift := 0
+  for _, instruction := range Instructions(program) {
+    if instruction.Opcode != 0 { continue }
+    // A shift by a register can't be told before running
+    if instruction.Operand > 3 { return 0 }
+    shift += int(instruction.Operand)
+  }
+  return shift
+}
+
+// Smallest value of register A that makes the program output itself, along with whether there's one. B and C start
+// as the given registers have them. Searching backwards is the fastest, constraint propagation over the output
+// formulas covers loops that shift A by a register, and anything else is left to a bounded check
+func FindQuine(registers Registers, program Program) (int, bool) {
+  search := quineSearch{registers, program, loopShift(program)}
+  if search.shift > 0 { return search.backward(0, len(program) - 1) }
+  formulas, windows, isBounded := search.formulas()
+  if isBounded { return search.solve(formulas, windows) }
+  return search.check()
+}
+
+// Whether FindQuine not finding a quine means there's none, rather than none below checkLimit
+func isExhaustive(registers Registers, program Program) bool {
+  if loopShift(program) > 0 { return true }
+  _, _, isBounded := quineSearch{registers, program, 0}.formulas()
+  return isBounded
+}
+
+type quineSearch struct {
+  registers Registers
+  program Program
+  shift int
+}
+
+// Every loop consumes the lowest bits of A and outputs one value, so the last value only depends on the highest bits
+// of A, the one before on those and the next ones down, and so on. A is built from the top down, shift bits at a time,
+// keeping the bits that make the program output the end of itself from the digit on. Bits are tried in ascending
+// order, so the first value found is the smallest
+func (q quineSearch) backward(regA int, digit int) (int, bool) {
+  if digit < 0 { return regA, true }
+  for bits := range 1 << q.shift {
+    candidate := regA << q.shift | bits
+    if !slices.Equal(q.run(candidate), Output(q.program[digit:])) { continue }
+    if traceSearch.On() { traceSearch.Printf("Digit %d: %o (octal) outputs %s\n", digit, candidate, Output(q.program[digit:])) }
+    found, isFound := q.backward(candidate, digit - 1)
+    if isFound { return found, true }
+  }
+  return 0, false
+}
+
+// Tries every value of A below checkLimit in turn
+func (q quineSearch) check() (int, bool) {
+  if traceSearch.On() { traceSearch.Printf("No constant shift of A per loop, checking the first %d values\n", checkLimit) }
+  for regA := range checkLimit {
+    if slices.Equal(q.run(regA), Output(q.program)) { return regA, true }
+  }
+  return 0, false
+}
+
+// Output of the program run with the given A. It stops as soon as the output gets longer than the program, since it
+// can no longer be the program then, or after maxSteps instructions
+func (q quineSearch) run(regA int) Output {
+  registers := q.registers
+  registers.A = regA
+  computer := NewComputer(registers)
+  for steps := 0; steps < maxSteps && len(computer.Output) <= len(q.program) && computer.Step(q.program); steps++ {}
+  return computer.Output
+}
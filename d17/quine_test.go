@@ -0,0 +1,28 @@
+package d17_test
+
+import (
+	"aoc2k24/d17"
+	"slices"
+	"testing"
+)
+
+func TestFindQuine(t *testing.T) {
+  regA, isFound := d17.FindQuine(d17.Registers{A: 2024}, quine)
+  if !isFound || regA != 117440 { t.Errorf("got %d (found: %t), expected 117440", regA, isFound) }
+
+  // Shifting A by register B rather than a literal can't be searched backwards, but its output formulas can be solved
+  registers := d17.Registers{B: 3}
+  program := d17.Program{0, 5, 5, 4, 3, 0}
+  regA, isFound = d17.FindQuine(registers, program)
+  registers.A = regA
+  output := d17.NewComputer(registers).Run(program)
+  if !isFound || !slices.Equal(output, d17.Output(program)) { t.Errorf("A=%d (found: %t) outputs %s", regA, isFound, output) }
+
+  // Every loop outputs 3, so the output never ends with the 0 of jnz
+  _, isFound = d17.FindQuine(d17.Registers{}, d17.Program{0, 3, 5, 3, 3, 0})
+  if isFound { t.Error("found a quine for a program that can't output itself") }
+
+  // Without a loop only the bounded check applies, and a single out can't output 4 values
+  _, isFound = d17.FindQuine(d17.Registers{}, d17.Program{0, 3, 5, 4})
+  if isFound { t.Error("found a quine for a program that outputs a single value") }
+}
@@ -0,0 +1,283 @@
+package d17
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// An expression over a, the initial value of register A. B and C start from values the input gives, so a is the only
+// unknown of a program
+type Expr interface {
+  Eval(a int) int
+  String() string
+}
+
+type Const int
+
+// a, the initial value of register A
+type Var struct{}
+
+// X >> By, what adv, bdv and cdv compute
+type Shr struct {
+  X, By Expr
+}
+
+type Xor struct {
+  X, Y Expr
+}
+
+// X & 7, the lowest 3 bits, like bst and out keep
+type Low struct {
+  X Expr
+}
+
+func (c Const) Eval(_ int) int { return int(c) }
+func (v Var) Eval(a int) int { return a }
+func (s Shr) Eval(a int) int { return s.X.Eval(a) >> s.By.Eval(a) }
+func (x Xor) Eval(a int) int { return x.X.Eval(a) ^ x.Y.Eval(a) }
+func (l Low) Eval(a int) int { return l.X.Eval(a) & 7 }
+
+func (c Const) String() string { return strconv.Itoa(int(c)) }
+func (v Var) String() string { return "a" }
+func (s Shr) String() string { return fmt.Sprintf("%s >> %s", operand(s.X), operand(s.By)) }
+func (x Xor) String() string { return fmt.Sprintf("%s ^ %s", xorOperand(x.X), xorOperand(x.Y)) }
+
+// The 3 bits of a from k on read as a[k:k+3], the rest as X & 7
+func (l Low) String() string {
+  from, isBits := bitsOfA(l.X)
+  if isBits { return fmt.Sprintf("a[%d:%d]", from, from + 3) }
+  return fmt.Sprintf("%s & 7", operand(l.X))
+}
+
+// Whether the expression is a shifted right by a constant, and by how much
+func bitsOfA(e Expr) (int, bool) {
+  switch e := e.(type) {
+  case Var:
+    return 0, true
+  case Shr:
+    by, isConst := e.By.(Const)
+    _, isVar := e.X.(Var)
+    return int(by), isConst && isVar
+  }
+  return 0, false
+}
+
+// Operands that aren't a constant, a or bits of a get parentheses
+func operand(e Expr) string {
+  switch e.(type) {
+  case Const, Var:
+    return e.String()
+  case Low:
+    _, isBits := bitsOfA(e.(Low).X)
+    if isBits { return e.String() }
+  }
+  return "(" + e.String() + ")"
+}
+
+// Xor is associative, so chains of it need no parentheses
+func xorOperand(e Expr) string {
+  _, isXor := e.(Xor)
+  if isXor { return e.String() }
+  return operand(e)
+}
+
+// Builders that fold constants and drop what can't change the value, keeping formulas short
+
+func shr(x Expr, by Expr) Expr {
+  byConst, isConst := by.(Const)
+  switch {
+  case isConst && byConst == 0:
+    return x
+  case isConst:
+    xConst, isXConst := x.(Const)
+    if isXConst { return xConst >> byConst }
+    inner, isShr := x.(Shr)
+    innerBy, isInnerConst := inner.By.(Const)
+    if isShr && isInnerConst { return Shr{inner.X, innerBy + byConst} }
+  }
+  return Shr{x, by}
+}
+
+func xor(x Expr, y Expr) Expr {
+  xConst, isXConst := x.(Const)
+  yConst, isYConst := y.(Const)
+  switch {
+  case isXConst && isYConst:
+    return xConst ^ yConst
+  case isXConst:
+    return xor(y, x)
+  case isYConst && yConst == 0:
+    return x
+  case isYConst:
+    // Constants gather on the right of a chain
+    inner, isXor := x.(Xor)
+    innerConst, isInnerConst := inner.Y.(Const)
+    if isXor && isInnerConst { return xor(inner.X, innerConst ^ yConst) }
+  }
+  return Xor{x, y}
+}
+
+func low(x Expr) Expr {
+  if isLow(x) { return x }
+  switch x := x.(type) {
+  case Const:
+    return x & 7
+  case Xor:
+    // The low bits of a xor are the xor of the low bits, which keeps & 7 on the terms it matters to
+    return xor(low(x.X), low(x.Y))
+  }
+  return Low{x}
+}
+
+// Whether the value is already below 8, so that & 7 wouldn't change it
+func isLow(e Expr) bool {
+  switch e := e.(type) {
+  case Const:
+    return e >= 0 && e < 8
+  case Low:
+    return true
+  case Xor:
+    return isLow(e.X) && isLow(e.Y)
+  }
+  return false
+}
+
+// The registers of a program run symbolically
+type symbolicRegisters struct {
+  A, B, C Expr
+}
+
+func (r symbolicRegisters) combo(operand uint8) Expr {
+  switch operand {
+  case 4:
+    return r.A
+  case 5:
+    return r.B
+  case 6:
+    return r.C
+  }
+  return Const(operand)
+}
+
+// Whether the program is a single loop back to the start, jumping at its end only, that outputs one value every time
+// round. The output formulas and the searches for quines rely on that shape
+func isSingleLoop(program Program) bool {
+  instructions := Instructions(program)
+  last := len(instructions) - 1
+  if last < 0 || instructions[last].Opcode != 3 || instructions[last].Operand != 0 { return false }
+  outputs := 0
+  for _, instruction := range instructions[:last] {
+    if instruction.Opcode == 3 { return false }
+    if instruction.Opcode == 5 { outputs++ }
+  }
+  return outputs == 1
+}
+
+// The first count outputs of a single loop program, each as a formula of a. The formula of output i holds as long as A
+// didn't reach 0 before, i.e. the program actually outputs that many values
+func Formulas(registers Registers, program Program, count int) ([]Expr, error) {
+  if !isSingleLoop(program) { return nil, fmt.Errorf("only programs made of a single loop with one out can be run symbolically") }
+  instructions := Instructions(program)
+  body := instructions[:len(instructions) - 1]
+  state := symbolicRegisters{Var{}, Const(registers.B), Const(registers.C)}
+  formulas := make([]Expr, 0, count)
+  for len(formulas) < count {
+    for _, instruction := range body {
+      combo := state.combo(instruction.Operand)
+      switch instruction.Opcode {
+      case 0:
+        state.A = shr(state.A, combo)
+      case 1:
+        state.B = xor(state.B, Const(instruction.Operand))
+      case 2:
+        state.B = low(combo)
+      case 4:
+        state.B = xor(state.B, state.C)
+      case 5:
+        formulas = append(formulas, low(combo))
+      case 6:
+        state.B = shr(state.A, combo)
+      case 7:
+        state.C = shr(state.A, combo)
+      }
+    }
+  }
+  return formulas, nil
+}
+
+// Bits of a, [From, To), that the low bits of an expression depend on
+type window struct {
+  From, To int
+}
+
+// Beyond this the bits of a can't be told apart in an int
+const maxBits = 62
+
+func (w window) isEmpty() bool { return w.From >= w.To }
+
+func (w window) union(other window) window {
+  if w.isEmpty() { return other }
+  if other.isEmpty() { return w }
+  return window{min(w.From, other.From), max(w.To, other.To)}
+}
+
+// Bits of a that bits [from, to) of the expression depend on. The window may reach past maxBits, when the expression
+// depends on bits that can't be bounded
+func dependencies(e Expr, from int, to int) window {
+  if from >= to { return window{} }
+  switch e := e.(type) {
+  case Var:
+    return window{from, to}
+  case Low:
+    return dependencies(e.X, from, min(to, 3))
+  case Xor:
+    return dependencies(e.X, from, to).union(dependencies(e.Y, from, to))
+  case Shr:
+    // The shift has to be bounded for the bits it reads to be
+    least, most := 0, maxBits
+    by, isConst := e.By.(Const)
+    if isConst { least, most = int(by), int(by) } else if isLow(e.By) { most = 7 }
+    shifted := dependencies(e.X, from + least, to + most)
+    return shifted.union(dependencies(e.By, 0, maxBits))
+  }
+  return window{}
+}
+
+// The formula of every output of a quine and the bits of a it depends on, if those are bounded
+func (q quineSearch) formulas() ([]Expr, []window, bool) {
+  formulas, err := Formulas(q.registers, q.program, len(q.program))
+  if err != nil { return nil, nil, false }
+  windows := make([]window, len(formulas))
+  for i, formula := range formulas {
+    windows[i] = dependencies(formula, 0, 3)
+    if windows[i].To > maxBits { return nil, nil, false }
+  }
+  return formulas, windows, true
+}
+
+// Finds the smallest quine by constraint propagation over the output formulas: each output fixes the bits of a in its
+// window that earlier outputs left free, keeping only the values that make it the value of the program there. Works
+// for single loop programs however they shift A, as long as every window is bounded
+func (q quineSearch) solve(formulas []Expr, windows []window) (int, bool) {
+  best, isFound := 0, false
+  var assign func(i int, a int, known int)
+  assign = func(i int, a int, known int) {
+    if i == len(formulas) {
+      // The formulas assume the loop runs as many times as the program is long, which only running it can confirm
+      if !isFound || a < best {
+        if slices.Equal(q.run(a), Output(q.program)) { best, isFound = a, true }
+      }
+      return
+    }
+    to := max(known, windows[i].To)
+    for free := range 1 << (to - known) {
+      candidate := a | free << known
+      if formulas[i].Eval(candidate) != int(q.program[i]) { continue }
+      if traceSearch.On() { traceSearch.Printf("Output %d: %o (octal) satisfies %s = %d\n", i, candidate, formulas[i], q.program[i]) }
+      assign(i + 1, candidate, to)
+    }
+  }
+  assign(0, 0, 0)
+  return best, isFound
+}
@@ -0,0 +1,32 @@
+package d17_test
+
+import (
+	"aoc2k24/d17"
+	"testing"
+)
+
+func TestFormulas(t *testing.T) {
+  formulas, err := d17.Formulas(d17.Registers{}, quine, 3)
+  if err != nil { t.Fatal(err) }
+  for i, expected := range []string{"a[3:6]", "a[6:9]", "a[9:12]"} {
+    if formulas[i].String() != expected { t.Errorf("output %d: got %s, expected %s", i, formulas[i], expected) }
+  }
+
+  _, err = d17.Formulas(d17.Registers{}, d17.Program{0, 3, 5, 4, 3, 0, 3, 0}, 3)
+  if err == nil { t.Error("got formulas for a program with two jumps") }
+}
+
+// Formulas give the values the computer outputs, for every A that outputs at least as many
+func TestFormulasMatchComputer(t *testing.T) {
+  // bst A, bxl 3, cdv B, bxc, bxl 6, adv 3, out B, jnz 0: shaped like the puzzle inputs, with C shifted by B
+  program := d17.Program{2, 4, 1, 3, 7, 5, 4, 0, 1, 6, 0, 3, 5, 5, 3, 0}
+  formulas, err := d17.Formulas(d17.Registers{}, program, 6)
+  if err != nil { t.Fatal(err) }
+  for a := range 1 << 16 {
+    output := d17.NewComputer(d17.Registers{A: a}).Run(program)
+    for i := range min(len(output), len(formulas)) {
+      value := formulas[i].Eval(a)
+      if value != int(output[i]) { t.Fatalf("a=%d, output %d: %s gives %d, the computer %d", a, i, formulas[i], value, output[i]) }
+    }
+  }
+}
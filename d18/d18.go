@@ -2,18 +2,22 @@ package d18
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
+	"aoc2k24/errs"
+	"aoc2k24/grid"
+	"aoc2k24/registry"
+	"aoc2k24/search"
+	"aoc2k24/trace"
 	"fmt"
-	"os"
+	"iter"
 	"os/exec"
 	"strconv"
 	"strings"
-	"time"
 )
 
-const isDebug = false
-const isRender = false
-const isPart2 = true
+var (
+  traceSearch = trace.New(constants.Eighteen, "search", trace.Debug, "Every position expanded by the path search, and the path found")
+  traceRender = trace.New(constants.Eighteen, "render", trace.Verbose, "Memory space and the path found, redrawn on every search")
+)
 
 type Color string
 
@@ -31,73 +35,12 @@ const (
   Ground = "░"
 )
 
-type Direction int 
-
-func (d Direction) getOpposite() Direction {
-  if d == None { return None }
-  if d == Up { return Down }
-  if d == Right { return Left }
-  if d == Down { return Up }
-  return Right
-}
-
-func (d Direction) getNext(c *Coord) Coord {
-  newC := Coord{c.x, c.y}
-  if d == None {
-    panic("You shouldn't do getNext over a NONE direction!")
-  } else if d == Up {
-    newC.y--
-  } else if d == Down {
-    newC.y++
-  } else if d == Right {
-    newC.x++
-  } else {
-    newC.x--
-  }
-  return newC
-}
-
-func (d Direction) toStr() string {
-  if d == None { return "N" }
-  if d == Up { return "U" }
-  if d == Right { return "R" }
-  if d == Down { return "D" }
-  return "L"
-}
-
-const (
-  Up Direction = iota
-  Right
-  Down
-  Left
-  None
-)
-
-var directions = [4]Direction{Up, Right, Down, Left}
-
-type Coord struct {
-  x int
-  y int
-}
-
-func (c Coord) toStr() string {
-  return fmt.Sprintf("(%d, %d)", c.x, c.y)
-}
-
-func (c Coord) equals(o *Coord) bool {
-  return c.x == o.x && c.y == o.y
-}
-
-func (c Coord) isOutside(m *Memory) bool {
-  return c.x < 0 || c.y < 0 || c.x >= m.width || c.y >= m.height
-}
-
-type Path []Coord
+type Path []grid.Coord
 
 func (p Path) toStr() string {
   output := ""
   for i, node := range p {
-    output += node.toStr()
+    output += node.String()
     if i < len(p) - 1 {
       output += " -> "
     }
@@ -105,244 +48,146 @@ func (p Path) toStr() string {
   return output
 }
 
-func (p Path) has(c *Coord) bool {
+func (p Path) has(c *grid.Coord) bool {
   for _, node := range p {
-    if node.equals(c) { return true }
+    if node == *c { return true }
   }
   return false
 }
 
+// Which bytes of the memory space are corrupted
 type Memory struct {
-  width int
-  height int
-  corrupted map[Coord]struct{}
+  corrupted *grid.Grid[bool]
 }
 
-func (m Memory) getHeuristic(dir Direction, c Coord) int {
-  xDiff := m.width - 1 - c.x; if xDiff < 0 { xDiff = -xDiff }
-  yDiff := m.height - 1 - c.y; if yDiff < 0 { yDiff = -yDiff }
-  h := xDiff + yDiff
-  if xDiff > yDiff && dir == Right || yDiff > xDiff && dir == Down {
-    h -= 1
-  } else if (xDiff > yDiff && dir == Down || dir == Up) || yDiff > xDiff && dir == Right || dir == Left {
-    h += 1
-  }
-  return h
-}
-
-type NextStep struct {
-  dir Direction
-  destination Coord
-  path Path
-  next *NextStep
-  prev *NextStep
+func init() {
+  registry.Register(constants.Eighteen, func() registry.Solver { return &solver{} })
 }
 
-type Frontier struct {
-  first *NextStep
-  last *NextStep
-  length int
+type solver struct {
+  lines []string
+  fallenBytes int
+  size int
 }
 
-func (f *Frontier) append(ns *NextStep) {
-  if f.length == 0 {
-    f.first = ns
-  }
-  ns.prev = f.last
-  if f.last != nil {
-    f.last.next = ns
+func (s *solver) Params() []registry.Param {
+  return []registry.Param{
+    {Name: "bytes", Default: 1024, Usage: "Bytes fallen before looking for a path in part 1, and before looking for the blocking one in part 2"},
+    {Name: "size", Default: 71, Min: 1, Usage: "Width and height of the memory space"},
   }
-  f.last = ns
-  f.length++
 }
 
-func (f *Frontier) pop() *NextStep {
-  if f.length == 0 {
-    return nil
+func (s *solver) SetParam(name string, value int) {
+  switch name {
+  case "bytes":
+    s.fallenBytes = value
+  case "size":
+    s.size = value
   }
-  s := f.first
-  f.length--
-  if f.length == 0 {
-    f.first = nil
-    f.last = nil
-  } else {
-    f.first = s.next
-    f.first.prev = nil
-  }
-  return s
-}
-
-func newFrontier(start Coord) *Frontier {
-  f := Frontier{nil, nil, 0}
-  f.append(&NextStep{None, start, Path{}, nil, nil})
-  return &f
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Eighteen, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Eighteen, ver, err))
+func (s *solver) Parse(lines []string) error {
+  for i, line := range lines {
+    coordVals := strings.Split(line, ",")
+    if len(coordVals) != 2 { return errs.Malformed("byte %d should be a x,y coordinate: %q", i + 1, line) }
+    for _, val := range coordVals {
+      n, err := strconv.Atoi(val)
+      if err != nil { return errs.Malformed("byte %d: %v", i + 1, err) }
+      if n < 0 || n >= s.size { return errs.Malformed("byte %d is outside the %dx%d memory space: %q", i + 1, s.size, s.size, line) }
+    }
   }
-  fallenBytes := 1024
-  if !isPart2 {
-    // Part 1
-    memory := getMemory(&lines, fallenBytes)
-    path := findShortestPath(memory)
-    renderPath(memory, path)
-  } else {
-    // Part 2
-    path := Path{}
-    tippingByte := Coord{-1, -1}
-    for {
-      memory := getMemory(&lines, fallenBytes)
-      path = *findShortestPath(memory)
-      // If there's no possible path, we've found the tipping corrupted byte
-      if len(path) == 0 { break }
-      // I keep dropping bytes until the latest dropped byte blocks the current shortest path
-      // Once that happens I start over and find a new shortest path
-      isInPath := false
-      for !isInPath {
-        fallenBytes++
-        tippingByte = parseCoord(lines[fallenBytes - 1])
-        isInPath = path.has(&tippingByte)
-      }
-      renderPath(memory, &path)
+  if s.fallenBytes > len(lines) { return errs.Malformed("only %d bytes fall, fewer than the %d expected", len(lines), s.fallenBytes) }
+  s.lines = lines
+  return nil
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  fallenBytes := s.fallenBytes
+  memory := getMemory(&s.lines, s.size, fallenBytes)
+  path := findShortestPath(memory)
+  if traceRender.On() { renderPath(memory, path) }
+  if len(*path) == 0 { return registry.Answer{}, errs.Unsolvable("the exit can't be reached after %d bytes have fallen", fallenBytes) }
+  // The path includes the starting position, which is not a step
+  return registry.Int(len(*path) - 1), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  fallenBytes := s.fallenBytes
+  path := Path{}
+  tippingByte := grid.Coord{X: -1, Y: -1}
+  for {
+    memory := getMemory(&s.lines, s.size, fallenBytes)
+    path = *findShortestPath(memory)
+    // If there's no possible path, we've found the tipping corrupted byte
+    if len(path) == 0 { break }
+    // I keep dropping bytes until the latest dropped byte blocks the current shortest path
+    // Once that happens I start over and find a new shortest path
+    isInPath := false
+    for !isInPath {
+      if fallenBytes == len(s.lines) { return registry.Answer{}, errs.Unsolvable("the exit is still reachable after every byte has fallen") }
+      fallenBytes++
+      tippingByte = parseCoord(s.lines[fallenBytes - 1])
+      isInPath = path.has(&tippingByte)
     }
-    fmt.Printf("The first corrupted byte that blocks every possible path to the exit is %s\n", tippingByte.toStr())
+    if traceRender.On() { renderPath(memory, &path) }
   }
+  if tippingByte.X == -1 { return registry.Answer{}, errs.Unsolvable("the exit is already blocked after the first %d bytes", fallenBytes) }
+  return registry.Text(fmt.Sprintf("%d,%d", tippingByte.X, tippingByte.Y)), nil
 }
 
+// Shortest path from the top left corner to the exit at the bottom right, both included. Empty if the exit can't be
+// reached
 func findShortestPath(m *Memory) *Path {
-  start := Coord{0, 0}
-  end := Coord{m.width - 1, m.height - 1}
-  shortest := Path{}
-  frontier := newFrontier(start)
-  seen := map[Coord]struct{}{start: {}}
-  for frontier.length > 0 {
-    nextStep(frontier.pop(), frontier, &seen, m, &end, &shortest)
-    if len(shortest) > 0 {
-      break
+  start := grid.Coord{X: 0, Y: 0}
+  end := grid.Coord{X: m.corrupted.Width - 1, Y: m.corrupted.Height - 1}
+  result := search.BFS(start, m.safeNeighbours, func(c grid.Coord) bool { return c == end })
+  path := Path(result.Path(end))
+  if traceSearch.On() {
+    if result.Found() {
+      traceSearch.Printf("Exit found [%d steps]!\n%s\n", len(path) - 1, path.toStr())
+    } else {
+      traceSearch.Print("The exit can't be reached\n")
     }
   }
-  return &shortest
+  return &path
 }
 
-func nextStep(step *NextStep, frontier *Frontier, seen *map[Coord]struct{}, m *Memory, end *Coord, shortest *Path) {
-  if isDebug { fmt.Printf("Analyzing node %s\n", step.destination.toStr()) }
-  step.path = append(step.path, step.destination)
-  log := fmt.Sprintf("Analyzing %s: path so far: %s\n", step.destination.toStr(), step.path.toStr())
-
-  // Second exit condition: Found the exit. If new path is shortest, set it to shortest variable
-  if step.destination.equals(end) {
-    if isDebug { fmt.Printf("Exit found [%d steps]!\n%s\n", len(step.path) - 1, step.path.toStr()) }
-    if len(*shortest) == 0 || len(step.path) < len(*shortest) {
-      if isDebug { fmt.Print("This is the new shortest path\n") }
-      *shortest = step.path
-    }
-    return
-  }
-  
-  for _, dir := range directions {
-    if isDebug { fmt.Printf("Explore direction %s from %s? ", dir.toStr(), step.destination.toStr()) }
-    // Skip direction conditions: Has already been visited, it's the direction we came from, next byte is outside memory space, or next byte is corrupted
-    if dir.getOpposite() == step.dir { if isDebug { fmt.Print("No, we came from here\n") }; continue }
-    newNode := dir.getNext(&step.destination) 
-    if newNode.isOutside(m) { if isDebug { fmt.Print("No, this steps out of memory\n") }; continue }
-    _, isCorrupted := m.corrupted[newNode]
-    if isCorrupted { if isDebug { fmt.Print("No, the next byte is corrupted\n") }; continue }
-    _, wasSeen := (*seen)[newNode]
-    if wasSeen { if isDebug { fmt.Print("No, it was visited before\n") }; continue }
-    // For each valid direction we add those new nodes to the back of the frontier queue
-    if isDebug { fmt.Print("Yes\n") }
-    (*seen)[newNode] = struct{}{}
-    newPath := make(Path, len(step.path))
-    for i := range len(step.path) {
-      newPath[i] = step.path[i]
+// Neighbours of the position inside the memory space that aren't corrupted
+func (m *Memory) safeNeighbours(c grid.Coord) iter.Seq[grid.Coord] {
+  if traceSearch.On() { traceSearch.Printf("Analyzing node %v\n", c) }
+  return func(yield func(grid.Coord) bool) {
+    for _, next := range m.corrupted.Neighbours4(c) {
+      if m.corrupted.Get(next) {
+        if traceSearch.On() { traceSearch.Printf("Byte %v is corrupted\n", next) }
+        continue
+      }
+      if !yield(next) { return }
     }
-    frontier.append(&NextStep{dir, newNode, newPath, nil, nil})
-  }
-
-  if isRender {
-    render(m, step, frontier)
-    fmt.Print(log)
-    time.Sleep(2000 * time.Millisecond)
-
   }
 }
 
-func getMemory(lines *[]string, fallenBytes int) *Memory {
-  m := Memory{0, 0, make(map[Coord]struct{})}
-  for i, byte := range *lines {
-    coordVals := strings.Split(byte, ",")
-    x, _ := strconv.Atoi(coordVals[0])
-    y, _ := strconv.Atoi(coordVals[1])
-    if x > m.width {
-      m.width = x
-    }
-    if y > m.height {
-      m.height = y
-    }
-    if i < fallenBytes {
-      c := Coord{x, y}
-      m.corrupted[c] = struct{}{}
-    }
+func getMemory(lines *[]string, size int, fallenBytes int) *Memory {
+  m := Memory{grid.New[bool](size, size)}
+  for _, byte := range (*lines)[:fallenBytes] {
+    m.corrupted.Set(parseCoord(byte), true)
   }
-  m.width++
-  m.height++
   return &m
 }
 
-func parseCoord(line string) Coord {
+func parseCoord(line string) grid.Coord {
   coordVals := strings.Split(line, ",")
   x, _ := strconv.Atoi(coordVals[0])
   y, _ := strconv.Atoi(coordVals[1])
-  return Coord{x, y}
-}
-
-func render(m *Memory, step *NextStep, frontier *Frontier) {
-  fMap := make(map[Coord]struct{})
-  s := frontier.first
-  for s != nil {
-    fMap[s.destination] = struct{}{}
-    s = s.next
-  }
-  clearScr()
-  for y := range m.height {
-    line := ""
-    for x := range m.width {
-      c := Coord{x, y}
-      _, isCorrupted := m.corrupted[c]
-      _, isFrontier := fMap[c]
-      isPath := step.path.has(&c)
-      if isCorrupted {
-        if isFrontier {
-          line += string(Red) + Wall + string(Reset)
-        } else {
-          line += string(White) + Wall + string(Reset)
-        }
-      } else if c.equals(&step.destination) {
-        line += string(Magenta) + step.dir.toStr() + string(Reset)
-      } else {
-        if isFrontier {
-          line += string(Red) + Ground + string(Reset)
-        } else if isPath {
-          line += string(Blue) + Ground + string(Reset)
-        } else {
-          line += string(Green) + Ground + string(Reset)
-        }
-      }
-    }
-    fmt.Printf("%s\n", line)
-  }
+  return grid.Coord{X: x, Y: y}
 }
 
 func renderPath(m *Memory, path *Path) {
   clearScr()
-  for y := range m.height {
+  for y := range m.corrupted.Height {
     line := ""
-    for x := range m.width {
-      c := Coord{x, y}
-      _, isCorrupted := m.corrupted[c]
+    for x := range m.corrupted.Width {
+      c := grid.Coord{X: x, Y: y}
+      isCorrupted := m.corrupted.Get(c)
       isPath := path.has(&c)
       if isCorrupted {
         line += string(White) + Wall + string(Reset)
@@ -354,14 +199,14 @@ func renderPath(m *Memory, path *Path) {
         }
       }
     }
-    fmt.Printf("%s\n", line)
+    traceRender.Printf("%s\n", line)
   }
-  fmt.Printf("Path: %d steps\n%s\n", len(*path) - 1, path.toStr())
+  traceRender.Printf("Path: %d steps\n%s\n", len(*path) - 1, path.toStr())
 }
 
 func clearScr() {
   cmd := exec.Command("clear")
-  cmd.Stdout = os.Stdout
+  cmd.Stdout = trace.Output()
   cmd.Run()
 }
 
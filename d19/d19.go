@@ -2,28 +2,45 @@ package d19
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 	"strings"
 )
 
-const isDebug = false
+var tracePatterns = trace.New(constants.Nineteen, "patterns", trace.Debug, "Designs and segments matched against the towels")
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Nineteen, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Nineteen, ver, err))
+func init() {
+  // Only the first part has been solved so far
+  registry.Register(constants.Nineteen, func() registry.Solver { return &solver{} }, constants.Part1)
+}
+
+type solver struct {
+  available *map[string]struct{}
+  desired *[]string
+}
+
+func (s *solver) Parse(lines []string) error {
+  if len(lines) < 2 || len(lines[1]) != 0 {
+    return errs.Malformed("expected the available patterns, an empty line and then the desired designs")
   }
-  available, desired := parsePatterns(&lines)
-  possible := findPossible(available, desired)
-  fmt.Printf("%d designs are possible\n", possible)
+  s.available, s.desired = parsePatterns(&lines)
+  return nil
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(findPossible(s.available, s.desired)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Answer{}, errs.Unsolvable("part 2 of day 19 hasn't been solved yet")
 }
 
 func findPossible(available *map[string]struct{}, desired *[]string) int {
   count := 0
   solved := make(map[string]struct{})
   for _, d := range *desired {
-    if isDebug { fmt.Printf("\n**** Desired pattern %s ****\n", d) }
+    if tracePatterns.On() { tracePatterns.Printf("\n**** Desired pattern %s ****\n", d) }
     count += solve(d, available, 0, len(d), &solved)
   }
   return count
@@ -32,20 +49,20 @@ func findPossible(available *map[string]struct{}, desired *[]string) int {
 func solve(d string, a *map[string]struct{}, start int, end int, solved *map[string]struct{}) int {
   // Check if this input has already been solved to avoid pursuing unnecesary recursion branches
   _, isSolved := (*solved)[d]; if isSolved {
-    if isDebug { fmt.Printf("Input %s has already been solved, skipping rest of branch\n", d) }
+    if tracePatterns.On() { tracePatterns.Printf("Input %s has already been solved, skipping rest of branch\n", d) }
     return 1
   }
 
   // End condition 1: End cursor reached start so everything matched
   if end == 0 {
-    if isDebug { fmt.Printf("Pattern %s is a match\n", d) }
+    if tracePatterns.On() { tracePatterns.Printf("Pattern %s is a match\n", d) }
     // Add to solved inputs
     (*solved)[d] = struct{}{}
     return 1
   }
   // End condition 2: Start cursor caught up with end cursor, so no match was found
   if start == end {
-    if isDebug { fmt.Printf("Pattern %s is NOT a match\n", d) }
+    if tracePatterns.On() { tracePatterns.Printf("Pattern %s is NOT a match\n", d) }
     return 0
   }
   
@@ -54,7 +71,7 @@ func solve(d string, a *map[string]struct{}, start int, end int, solved *map[str
   // Branch 2: Continue as if this wasn't a match
   _, isMatch := (*a)[d[start:end]]
   if isMatch {
-    if isDebug { fmt.Printf("Segment %s is a match\n", d[start:end]) }
+    if tracePatterns.On() { tracePatterns.Printf("Segment %s is a match\n", d[start:end]) }
     res := solve(d, a, start + 1, end, solved)
     res += solve(d, a, 0, start, solved)
     if res > 0 { return 1 }
@@ -62,7 +79,7 @@ func solve(d string, a *map[string]struct{}, start int, end int, solved *map[str
   }
 
   // If no match, advance start by 1 and recurse
-  if isDebug { fmt.Printf("Segment %s is NOT a match\n", d[start:end]) }
+  if tracePatterns.On() { tracePatterns.Printf("Segment %s is NOT a match\n", d[start:end]) }
   return solve(d, a, start + 1, end, solved)
 }
 
@@ -2,28 +2,47 @@ package d2
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 	"strconv"
 	"strings"
 )
 
-const isDebug = false
+var traceReports = trace.New(constants.Two, "reports", trace.Debug, "Reports checked and the sub-reports tried when dampening")
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Two, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Two, ver, err))
+func init() {
+  registry.Register(constants.Two, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  reports [][]int
+}
+
+func (s *solver) Parse(lines []string) error {
+  s.reports = make([][]int, len(lines))
+  for i, line := range lines {
+    nums, err := getNums(strings.Split(line, " "))
+    if err != nil {
+      return errs.Malformed("report %d: %v", i + 1, err)
+    }
+    s.reports[i] = nums
   }
-  fmt.Printf("Total safe reports (part 1): %d\n", processReports(lines, false))
-  fmt.Printf("Total safe reports (part 2): %d\n", processReports(lines, true))
+  return nil
 }
 
-func processReports(lines []string, isPart2 bool) int {
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(processReports(s.reports, false)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Int(processReports(s.reports, true)), nil
+}
+
+func processReports(reports [][]int, isPart2 bool) int {
   safeReports := 0
-  for i, line := range lines {
-    nums := getNums(strings.Split(line, " "))
-    if isDebug { fmt.Printf("Processing report #%d: %s - part 2? %v\n", i, line, isPart2) }
+  for i, nums := range reports {
+    if traceReports.On() { traceReports.Printf("Processing report #%d: %v - part 2? %v\n", i, nums, isPart2) }
     isSafe := isReportSafe(nums, getNew(nums, -1), isPart2)
     if isSafe { safeReports++ }
   }
@@ -40,7 +59,7 @@ func isReportSafe(orig []int, nums []int, isPart2 bool) bool {
   if isPart2 && len(orig) == len(nums) {
     for skip := 0; skip < len(orig); skip++ {
       newNums := getNew(orig, skip)
-      if isDebug { fmt.Printf("Analyzing sub %v\n", newNums) }
+      if traceReports.On() { traceReports.Printf("Analyzing sub %v\n", newNums) }
       isSafe := isReportSafe(orig, newNums, isPart2)
       if isSafe { return true }
     }
@@ -68,14 +87,14 @@ func findTrend(nums []int) bool {
 
 func updateUnsafeCount(nums []int, isAscending bool, unsafeCount *int) {
   if len(nums) == 1 { 
-    if isDebug { fmt.Printf("Finished examining report. Unsafe count: %d\n\n", *unsafeCount) }
+    if traceReports.On() { traceReports.Printf("Finished examining report. Unsafe count: %d\n\n", *unsafeCount) }
     return
   }
   safe := isValidProgression(nums[0], nums[1], isAscending)
   if !safe {
     (*unsafeCount)++
   }
-  if isDebug { fmt.Printf("Is pair %d, %d safe? %v\n", nums[0], nums[1], safe) }
+  if traceReports.On() { traceReports.Printf("Is pair %d, %d safe? %v\n", nums[0], nums[1], safe) }
   updateUnsafeCount(nums[1:], isAscending, unsafeCount)
 }
 
@@ -85,10 +104,13 @@ func isValidProgression(v1 int, v2 int, isAscending bool) bool {
   return diff > 0 && diff <= 3 && (!isAscending && v1 > v2 || isAscending && v1 < v2)
 }
 
-func getNums(strVals []string) []int {
+func getNums(strVals []string) ([]int, error) {
   numVals := make([]int, len(strVals))
   for i := range len(strVals) {
-    numVals[i], _ = strconv.Atoi(strVals[i])
+    num, err := strconv.Atoi(strVals[i])
+    if err != nil { return nil, err }
+    numVals[i] = num
   }
-  return numVals
+  return numVals, nil
 }
+
@@ -2,8 +2,8 @@ package d3
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 	"strconv"
 	"strings"
 )
@@ -30,18 +30,28 @@ func (s State) ToString() string {
   return "don't()"
 }
 
-const isDebug = false
+var traceParser = trace.New(constants.Three, "parser", trace.Debug, "Candidate instructions and do/don't state changes")
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Three, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Three, ver, err))
-  }
-  part1Result := getMulSums(&lines, false)
-  fmt.Printf("Part 1 result: %d\n\n", part1Result)
+func init() {
+  registry.Register(constants.Three, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  lines []string
+}
+
+// Corrupted memory is expected, so any input is valid
+func (s *solver) Parse(lines []string) error {
+  s.lines = lines
+  return nil
+}
 
-  part2Result := getMulSums(&lines, true)
-  fmt.Printf("Part 2 result: %d\n\n", part2Result)
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(getMulSums(&s.lines, false)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Int(getMulSums(&s.lines, true)), nil
 }
 
 func getMulSums(lines *[]string, isPart2 bool) int {
@@ -82,20 +92,27 @@ func getOperands(line *string, start int, candidate string) (int, int) {
     updateLine(line, start + len(candidate))
     return -1, -1
   }
-  if isDebug { fmt.Printf("Numbers: %d, %d\n", n1, n2) }
+  if traceParser.On() { traceParser.Printf("Numbers: %d, %d\n", n1, n2) }
   return n1, n2
 }
 
 func getCandidatePart2(line *string, currState *State) (int, string) {
   nextStateChangeIndex := getNextStateChangeIndex(line, currState)
   start := strings.Index(*line, "mul(")
-  if isDebug { fmt.Printf("Next state change: %d | Next candidate: %d\n", nextStateChangeIndex, start) }
+  // Past the last candidate of the line, only a state change still matters, as it carries over to the next line
+  isLineDone := start < 0
+  if isLineDone { start = len(*line) }
+  if traceParser.On() { traceParser.Printf("Next state change: %d | Next candidate: %d\n", nextStateChangeIndex, start) }
   if nextStateChangeIndex >= 0 && nextStateChangeIndex < start {
     currState.flip()
-    if isDebug { fmt.Print("Switching state\n") }
+    if traceParser.On() { traceParser.Print("Switching state\n") }
+  }
+  if isLineDone {
+    updateLine(line, start)
+    return -1, ""
   }
   if *currState == Dont { 
-    if isDebug { fmt.Print("State is DON'T so skipping this candidate\n") }
+    if traceParser.On() { traceParser.Print("State is DON'T so skipping this candidate\n") }
     updateLine(line, start+4)
     return -1, "" 
   }
@@ -110,7 +127,7 @@ func getCandidatePart2(line *string, currState *State) (int, string) {
   }
   end = strings.Index(candidate, ")") + 1
   candidate = candidate[:end]
-  if isDebug { fmt.Printf("Candidate: %s ", candidate) }
+  if traceParser.On() { traceParser.Printf("Candidate: %s ", candidate) }
   return start, candidate
 }
 
@@ -124,6 +141,10 @@ func getNextStateChangeIndex(line *string, currState *State) int {
 
 func getCandidate(line *string) (int, string) {
   start := strings.Index(*line, "mul(")
+  if start < 0 {
+    updateLine(line, len(*line))
+    return -1, ""
+  }
   end := start + 13
   if end > len(*line) {
     end = len(*line)
@@ -135,7 +156,7 @@ func getCandidate(line *string) (int, string) {
   }
   end = strings.Index(candidate, ")") + 1
   candidate = candidate[:end]
-  if isDebug { fmt.Printf("Candidate: %s ", candidate) }
+  if traceParser.On() { traceParser.Printf("Candidate: %s ", candidate) }
   return start, candidate
 }
 
@@ -0,0 +1,40 @@
+package d3_test
+
+import (
+	"aoc2k24/constants"
+	_ "aoc2k24/d3"
+	"aoc2k24/registry"
+	"testing"
+)
+
+func solve(t *testing.T, lines []string) (string, string) {
+  t.Helper()
+  entry, _ := registry.Get(constants.Three)
+  solver := entry.New()
+  err := solver.Parse(lines)
+  if err != nil { t.Fatal(err) }
+  part1, err := solver.Part1()
+  if err != nil { t.Fatal(err) }
+  part2, err := solver.Part2()
+  if err != nil { t.Fatal(err) }
+  return part1.String(), part2.String()
+}
+
+func TestMulSums(t *testing.T) {
+  tests := []struct {
+    name string
+    lines []string
+    part1 string
+    part2 string
+  }{
+    {"example", []string{"xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))"}, "161", "48"},
+    {"no mul", []string{"hello world, nothing here"}, "0", "0"},
+    {"text after the last mul", []string{"mul(2,3) and then some text", "mul(4,5)"}, "26", "26"},
+    // A don't() after the last mul of a line still disables the mul of the next one
+    {"state change after the last mul", []string{"mul(2,3) don't() xx", "mul(4,5)"}, "26", "6"},
+  }
+  for _, test := range tests {
+    part1, part2 := solve(t, test.lines)
+    if part1 != test.part1 || part2 != test.part2 { t.Errorf("%s: got %s and %s, expected %s and %s", test.name, part1, part2, test.part1, test.part2) }
+  }
+}
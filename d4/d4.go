@@ -2,148 +2,104 @@ package d4
 
 import (
 	"aoc2k24/constants"
-	"fmt"
-	"aoc2k24/io"
+	"aoc2k24/grid"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 )
 
-const isDebug = false
-
-type Direction int
-
-const (
-  U Direction = iota
-  UR
-  R
-  DR
-  D
-  DL
-  L
-  UL
-)
-
-var dirList = [8]Direction{U, UR, R, DR, D, DL, L, UL}
+var traceMatches = trace.New(constants.Four, "matches", trace.Debug, "Every XMAS and X-MAS match found, or missed")
 
+// The word search, and where each X and A are, since they're where part 1 and part 2 matches are centered
 type Salad struct {
-  height int
-  width int
-  letters []rune
+  letters *grid.Grid[rune]
+  xs []grid.Coord
+  as []grid.Coord
 }
 
-func newSalad(lines []string) (*Salad, *map[int]struct{}, *map[int]struct{}) {
-  height := len(lines)
-  width := len(lines[0])
-  salad := Salad{height, width, make([]rune, height * width)}
-  xMap := make(map[int]struct{})
-  aMap := make(map[int]struct{})
-  for y, line := range lines {
-    for x, char := range line {
-      salad.letters[width * y + x] = rune(char)
-      if char == 'X' {
-        xMap[width * y + x] = struct{}{}
-      } else if char == 'A' {
-        aMap[width * y + x] = struct{}{}
-      }
+func newSalad(letters *grid.Grid[rune]) *Salad {
+  salad := Salad{letters, []grid.Coord{}, []grid.Coord{}}
+  for c, letter := range letters.All() {
+    if letter == 'X' {
+      salad.xs = append(salad.xs, c)
+    } else if letter == 'A' {
+      salad.as = append(salad.as, c)
     }
   }
-  return &salad, &xMap, &aMap
+  return &salad
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Four, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Four, ver, err))
-  }
-  salad, xMap, aMap := newSalad(lines)
-  p1Result := getP1Result(salad, xMap)
-  p2Result := getP2Result(salad, aMap)
-  fmt.Printf("Part 1 result: %d\n", p1Result)
-  fmt.Printf("Part 2 result: %d\n", p2Result)
+func init() {
+  registry.Register(constants.Four, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  salad *Salad
 }
 
-func getP1Result(salad *Salad, xMap *map[int]struct{}) int {
+func (s *solver) Parse(lines []string) error {
+  letters, err := grid.Parse(lines)
+  if err != nil { return err }
+  s.salad = newSalad(letters)
+  return nil
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(getP1Result(s.salad)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Int(getP2Result(s.salad)), nil
+}
+
+// Looks for the rest of the word in the 8 directions around every X
+func getP1Result(salad *Salad) int {
   w := "MAS"
-  height := salad.height
-  width := salad.width
   count := 0
-  for i := range *xMap {
-    y := i / width
-    x := i % width
-    hasSpaceUp := i - width * 3 >= 0
-    hasSpaceDown := i + width * 3 < height * width
-    hasSpaceRight := i % width < width - 3
-    hasSpaceLeft := i % width > 2
+  for _, x := range salad.xs {
     cb := count
-    // Look up
-    if hasSpaceUp && w == string(salad.letters[i-width]) + string(salad.letters[i-width*2]) + string(salad.letters[i-width*3]) {
-      count++
-      if isDebug { fmt.Printf("Found UP match for X in x %d, y %d\n", x, y) }
-    }
-    // Look right
-    if hasSpaceRight && w == string(salad.letters[i+1]) + string(salad.letters[i+2]) + string(salad.letters[i+3]) {
+    for _, delta := range grid.Deltas8 {
+      if !hasWord(salad.letters, x, delta, w) { continue }
       count++
-      if isDebug { fmt.Printf("Found RIGHT match for X in x %d, y %d\n", x, y) }
+      if traceMatches.On() { traceMatches.Printf("Found match towards %s for X in x %d, y %d\n", delta, x.X, x.Y) }
     }
-    // Look down
-    if hasSpaceDown && w == string(salad.letters[i+width]) + string(salad.letters[i+width*2]) + string(salad.letters[i+width*3]) {
-      count++
-      if isDebug { fmt.Printf("Found DOWN match for X in x %d, y %d\n", x, y) }
-    }
-    // Look left
-    if hasSpaceLeft && w == string(salad.letters[i-1]) + string(salad.letters[i-2]) + string(salad.letters[i-3]) {
-      count++
-      if isDebug { fmt.Printf("Found LEFT match for X in x %d, y %d\n", x, y) }
-    }
-    // Look up/right
-    if hasSpaceUp && hasSpaceRight && w == string(salad.letters[i-width+1]) + string(salad.letters[i-width*2+2]) + string(salad.letters[i-width*3+3]) {
-      count++
-      if isDebug { fmt.Printf("Found UP/RIGHT match for X in x %d, y %d\n", x, y) }
-    }
-    // Look down/right
-    if hasSpaceDown && hasSpaceRight && w == string(salad.letters[i+width+1]) + string(salad.letters[i+width*2+2]) + string(salad.letters[i+width*3+3]) {
-      count++
-      if isDebug { fmt.Printf("Found DOWN/RIGHT match for X in x %d, y %d\n", x, y) }
-    }
-    // Look down/left
-    if hasSpaceDown && hasSpaceLeft && w == string(salad.letters[i+width-1]) + string(salad.letters[i+width*2-2]) + string(salad.letters[i+width*3-3]) {
-      count++
-      if isDebug { fmt.Printf("Found DOWN/LEFT match for X in x %d, y %d\n", x, y) }
-    }
-    // Look up/left
-    if hasSpaceUp && hasSpaceLeft && w == string(salad.letters[i-width-1]) + string(salad.letters[i-width*2-2]) + string(salad.letters[i-width*3-3]) {
-      count++
-      if isDebug { fmt.Printf("Found UP/LEFT match for X in x %d, y %d\n", x, y) }
-    }
-    if isDebug && cb == count {
-      fmt.Printf("No match found for X in x %d, y %d\n", x, y)
+    if traceMatches.On() && cb == count {
+      traceMatches.Printf("No match found for X in x %d, y %d\n", x.X, x.Y)
     }
   }
   return count
 }
 
-func getP2Result(salad *Salad, aMap *map[int]struct{}) int {
-  height := salad.height
-  width := salad.width
+func hasWord(letters *grid.Grid[rune], from grid.Coord, delta grid.Coord, word string) bool {
+  c := from
+  for _, char := range word {
+    c = c.Add(delta)
+    letter, isInside := letters.Lookup(c)
+    if !isInside || letter != char { return false }
+  }
+  return true
+}
+
+// Both diagonals crossing an A have to read MAS, in either direction
+func getP2Result(salad *Salad) int {
   count := 0
-  for i := range *aMap {
+  for _, a := range salad.as {
     cb := count
-    x := i % width
-    y := i / width
-    hasSpaceAround := i - width >= 0 && i + width < height * width && x - 1 >= 0 && x + 1 < width
+    hasSpaceAround := a.X > 0 && a.Y > 0 && a.X < salad.letters.Width - 1 && a.Y < salad.letters.Height - 1
     if !hasSpaceAround { 
-      if isDebug { fmt.Printf("No space for A in x %d, y %d\n", x, y) }
+      if traceMatches.On() { traceMatches.Printf("No space for A in x %d, y %d\n", a.X, a.Y) }
       continue 
     }
-    topLeft := i - width - 1
-    topRight := i - width + 1
-    bottomRight := i + width + 1
-    bottomLeft := i + width - 1
-    if (salad.letters[topLeft] == 'M' && salad.letters[bottomRight] == 'S' || salad.letters[topLeft] == 'S' && salad.letters[bottomRight] == 'M') &&
-      (salad.letters[topRight] == 'M' && salad.letters[bottomLeft] == 'S' || salad.letters[topRight] == 'S' && salad.letters[bottomLeft] == 'M') {
-      if isDebug { fmt.Printf("Match found for A in x %d, y %d\n", x, y) }
+    topLeft := salad.letters.Get(a.Add(grid.Coord{X: -1, Y: -1}))
+    topRight := salad.letters.Get(a.Add(grid.Coord{X: 1, Y: -1}))
+    bottomRight := salad.letters.Get(a.Add(grid.Coord{X: 1, Y: 1}))
+    bottomLeft := salad.letters.Get(a.Add(grid.Coord{X: -1, Y: 1}))
+    if (topLeft == 'M' && bottomRight == 'S' || topLeft == 'S' && bottomRight == 'M') &&
+      (topRight == 'M' && bottomLeft == 'S' || topRight == 'S' && bottomLeft == 'M') {
+      if traceMatches.On() { traceMatches.Printf("Match found for A in x %d, y %d\n", a.X, a.Y) }
       count++
     }
-    if isDebug && cb == count {
-      fmt.Printf("No match found for A in x %d, y %d\n", x, y)
+    if traceMatches.On() && cb == count {
+      traceMatches.Printf("No match found for A in x %d, y %d\n", a.X, a.Y)
     }
   }
   return count
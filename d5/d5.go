@@ -2,7 +2,8 @@ package d5
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
 	"fmt"
 	"slices"
 	"sort"
@@ -12,15 +13,39 @@ import (
 
 type RuleMap map[int][]int
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Five, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Five, ver, err))
-  }
-  rules, updates := parseEntry(&lines)
-  part1Result, part2Result := solve(rules, updates)
-  fmt.Printf("Part 1 result: %d\n", part1Result)
-  fmt.Printf("Part 2 result: %d\n", part2Result)
+func init() {
+  registry.Register(constants.Five, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  rules *RuleMap
+  updates *[][]int
+  isSolved bool
+  part1Result int
+  part2Result int
+}
+
+func (s *solver) Parse(lines []string) error {
+  var err error
+  s.rules, s.updates, err = parseEntry(&lines)
+  return err
+}
+
+// Both parts come out of the same pass (which sorts the updates in place), so it only runs once
+func (s *solver) solve() {
+  if s.isSolved { return }
+  s.part1Result, s.part2Result = solve(s.rules, s.updates)
+  s.isSolved = true
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  s.solve()
+  return registry.Int(s.part1Result), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  s.solve()
+  return registry.Int(s.part2Result), nil
 }
 
 func solve(rules *RuleMap, updates *[][]int) (int, int) {
@@ -67,24 +92,31 @@ func isUpdateInOrder(update *[]int, rm *RuleMap) bool {
   return true
 }
 
-func parseEntry(lines *[]string) (*RuleMap, *[][]int) {
+func parseEntry(lines *[]string) (*RuleMap, *[][]int, error) {
   rules := RuleMap(make(map[int][]int))
   updates := make([][]int, 0)
-  for _, line := range *lines {
+  for i, line := range *lines {
     if len(line) == 0 { continue }
+    var err error
     if strings.Contains(line, "|") {
-      addRule(&line, &rules)
+      err = addRule(&line, &rules)
     } else {
-      addUpdate(&line, &updates)
+      err = addUpdate(&line, &updates)
+    }
+    if err != nil {
+      return nil, nil, errs.Malformed("line %d: %v", i + 1, err)
     }
   }
-  return &rules, &updates
+  return &rules, &updates, nil
 }
 
-func addRule(line *string, rules *RuleMap) {
+func addRule(line *string, rules *RuleMap) error {
   ruleComponents := strings.Split(*line, "|")
-  n1, _ := strconv.Atoi(ruleComponents[0])
-  n2, _ := strconv.Atoi(ruleComponents[1])
+  if len(ruleComponents) != 2 { return fmt.Errorf("rule %q should be two pages separated by |", *line) }
+  n1, err := strconv.Atoi(ruleComponents[0])
+  if err != nil { return err }
+  n2, err := strconv.Atoi(ruleComponents[1])
+  if err != nil { return err }
   _, exists := (*rules)[n1]
   if exists {
     (*rules)[n1] = append((*rules)[n1], n2)
@@ -92,14 +124,18 @@ func addRule(line *string, rules *RuleMap) {
     (*rules)[n1] = make([]int, 1)
     (*rules)[n1][0] = n2
   }
+  return nil
 }
 
-func addUpdate(line *string, updates *[][]int) {
+func addUpdate(line *string, updates *[][]int) error {
   updComponents := strings.Split(*line, ",")
   update := make([]int, len(updComponents))
   for i, strnum := range updComponents {
-    num, _ := strconv.Atoi(strnum)
+    num, err := strconv.Atoi(strnum)
+    if err != nil { return err }
     update[i] = num
   }
   (*updates) = append(*updates, update)
+  return nil
 }
+
@@ -2,83 +2,66 @@ package d6
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
+	"aoc2k24/errs"
+	"aoc2k24/grid"
+	"aoc2k24/registry"
 )
 
-type Direction int
+type Floorplan struct {
+  tiles *grid.Grid[rune]
+  guardPos grid.Coord
+}
 
-const (
-  North Direction = iota
-  East
-  South
-  West
-)
+// Obstructions are the # tiles, plus an extra one when part 2 tries to trap the guard in a loop
+type Obstructions struct {
+  floorplan *Floorplan
+  extra grid.Coord
+  hasExtra bool
+}
 
-func (d Direction) turnRight() Direction {
-  if d == North {
-    return East
-  }
-  if d == East {
-    return South
-  }
-  if d == South {
-    return West
-  }
-  return North
+func (o Obstructions) has(c grid.Coord) bool {
+  return o.floorplan.tiles.Get(c) == '#' || o.hasExtra && c == o.extra
 }
 
-type Floorplan struct {
-  guardPos int
-  width int
-  height int
+func init() {
+  registry.Register(constants.Six, func() registry.Solver { return &solver{} })
 }
 
-func newFloorplan(lines []string) (*Floorplan, *map[int]struct{}) {
-  floorplan := Floorplan{-1, len(lines[0]), len(lines)}
-  obstructions := make(map[int]struct{})
-  for y, line := range lines {
-    for x, char := range line {
-      if char == '#' {
-        i := y * floorplan.width + x
-        obstructions[i] = struct{}{}
-      } else if char == '^' {
-        i := y * floorplan.width + x
-        floorplan.guardPos = i
-      }
-    }
-  }
-  return &floorplan, &obstructions
+type solver struct {
+  floorplan *Floorplan
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Six, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Six, ver, err))
-  }
-  floorplan, obstructions := newFloorplan(lines)
-  part1Result, path := solvePart1(floorplan, *obstructions)
-  part2Result := solvePart2(floorplan, *obstructions, path)
-  fmt.Printf("Part 1 result: %d\n", part1Result)
-  fmt.Printf("Part 2 result: %d\n", part2Result)
+func (s *solver) Parse(lines []string) error {
+  tiles, err := grid.Parse(lines)
+  if err != nil { return err }
+  guardPos, hasGuard := grid.Find(tiles, '^')
+  if !hasGuard { return errs.Malformed("floorplan has no guard (^)") }
+  s.floorplan = &Floorplan{tiles, guardPos}
+  return nil
 }
 
-func solvePart2(floorplan *Floorplan, obstructions map[int]struct{}, path *[]int) int {
+func (s *solver) Part1() (registry.Answer, error) {
+  part1Result, _ := walk(Obstructions{floorplan: s.floorplan})
+  if part1Result == -1 { return registry.Answer{}, errs.Unsolvable("the guard never leaves the mapped area") }
+  return registry.Int(part1Result), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  // Part 2 only places new obstructions along the path walked in part 1
+  _, path := walk(Obstructions{floorplan: s.floorplan})
+  return registry.Int(solvePart2(s.floorplan, path)), nil
+}
+
+func solvePart2(floorplan *Floorplan, path []grid.Coord) int {
   loopCount := 0
-  usedObstructions := make(map[int]struct{})
-  var obsIn int
-  for i := 1; i < len(*path); i++ {
-    newObs := make(map[int]struct{})
-    obsIn = (*path)[i]
-    newObs[obsIn] = struct{}{}
+  usedObstructions := make(map[grid.Coord]struct{})
+  for i := 1; i < len(path); i++ {
+    obsIn := path[i]
     _, alreadyUsed := usedObstructions[obsIn]; if alreadyUsed {
       continue 
     }
     usedObstructions[obsIn] = struct{}{}
-    for obs := range obstructions {
-      newObs[obs] = struct{}{}
-    }
-    visited, _ := solvePart1(floorplan, newObs)
+    visited, _ := walk(Obstructions{floorplan, obsIn, true})
     if visited == -1 {
       loopCount++
     }
@@ -86,36 +69,28 @@ func solvePart2(floorplan *Floorplan, obstructions map[int]struct{}, path *[]int
   return loopCount
 }
 
-func solvePart1(floorplan *Floorplan, obstructions map[int]struct{}) (int, *[]int) {
-  path := make([]int, 0)
-  visited := make(map[int]struct{})
-  turnMap := make(map[string]struct{})
-  dir := North
-  y := floorplan.guardPos / floorplan.width
-  x := floorplan.guardPos % floorplan.width
+// Walks the guard until it leaves the floorplan, returning how many tiles it visited and the path it took.
+// Turning twice at the same place facing the same way means it's stuck in a loop, and then -1 is returned
+func walk(obstructions Obstructions) (int, []grid.Coord) {
+  floorplan := obstructions.floorplan
+  path := make([]grid.Coord, 0)
+  visited := make(map[grid.Coord]struct{})
+  type turn struct {
+    pos grid.Coord
+    dir grid.Direction
+  }
+  turnMap := make(map[turn]struct{})
+  dir := grid.Up
+  current := floorplan.guardPos
   for true {
-    nextX := x
-    nextY := y - 1
-    if dir == East {
-      nextX = x + 1
-      nextY = y
-    } else if dir == South {
-      nextX = x
-      nextY = y + 1
-    } else if dir == West {
-      nextX = x - 1
-      nextY = y
-    }
-    current := y * floorplan.width + x
-    next := nextY * floorplan.width + nextX
-    _, isObstructed := obstructions[next]
-    if isObstructed {
-      _, hasTurnedHereBefore := turnMap[fmt.Sprintf("%d-%d", current, dir)]
+    next := current.Step(dir)
+    if floorplan.tiles.Contains(next) && obstructions.has(next) {
+      _, hasTurnedHereBefore := turnMap[turn{current, dir}]
       if hasTurnedHereBefore {
-        return -1, &path
+        return -1, path
       }
-      turnMap[fmt.Sprintf("%d-%d", current, dir)] = struct{}{}
-      dir = dir.turnRight()
+      turnMap[turn{current, dir}] = struct{}{}
+      dir = dir.TurnRight()
       continue
     }
     path = append(path, current)
@@ -123,9 +98,8 @@ func solvePart1(floorplan *Floorplan, obstructions map[int]struct{}) (int, *[]in
     if !wasVisited { 
       visited[current] = struct{}{}
     }
-    if nextX < 0 || nextY < 0 || nextX >= floorplan.width || nextY >= floorplan.height { break }
-    x = nextX
-    y = nextY
+    if !floorplan.tiles.Contains(next) { break }
+    current = next
   }
-  return len(visited), &path
+  return len(visited), path
 }
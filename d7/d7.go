@@ -2,119 +2,215 @@ package d7
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
 
-const isDebug = false
+var traceEquations = trace.New(constants.Seven, "equations", trace.Debug, "Equations and the operator sequences that solve them")
 
+// Index of an operation in the list of operations
 type Operator int
 
-type OperandNode struct {
-  next *OperandNode
-  value int
+const (
+  Addition Operator = iota
+  Product
+  Concatenation
+  Subtraction
+  Exponent
+)
+
+// Apply combines the value so far with the next operand. Undo goes the other way: given the result and the last
+// operand, it finds the value there was before, or reports that none gives that result (e.g. a product that doesn't
+// divide), which is what prunes the search
+type Operation struct {
+  Symbol string
+  Apply func(left int, right int) int
+  Undo func(result int, right int) (int, bool)
 }
 
-type OperandList struct {
-  first *OperandNode
-  last *OperandNode
-  length int
+// Every operation, in the order they're tried. Part 1 uses the first two, part 2 the first three unless its operators
+// parameter says otherwise. Subtraction and exponent aren't part of the puzzle, but can be tried with that parameter
+var operations = []Operation{
+  Addition: {"+", add, undoAdd},
+  Product: {"*", multiply, divide},
+  Concatenation: {"||", concatenate, truncate},
+  Subtraction: {"-", subtract, undoSubtract},
+  Exponent: {"^", power, root},
 }
 
-func (o *OperandList) append(operand int) {
-  node := OperandNode{nil, operand}
-  if o.length == 0 {
-    o.first = &node
-    o.last = &node
-  } else {
-    o.last.next = &node
-    o.last = &node
+// Adds an operation to the end of the list, so that part 2 can try it by raising its operators parameter. Meant to be
+// called from an init function, like registry.Register
+func RegisterOperation(operation Operation) Operator {
+  for _, existing := range operations {
+    if existing.Symbol == operation.Symbol { panic(fmt.Sprintf("Operation %s is registered more than once", operation.Symbol)) }
   }
-  o.length++
+  operations = append(operations, operation)
+  return Operator(len(operations) - 1)
 }
 
-func newOperandList(operands *[]int) *OperandList {
-  operandList := OperandList{nil, nil, 0}
-  for _, o := range *operands {
-    operandList.append(o)
+type Equation struct {
+  result int
+  operands []int
+}
+
+// Operators between the operands, from left to right
+type Sequence []Operator
+
+// The equation written out with the operators of the sequence, e.g. 81 * 40 + 27
+func (e Equation) Format(sequence Sequence) string {
+  var written strings.Builder
+  written.WriteString(strconv.Itoa(e.operands[0]))
+  for i, operator := range sequence {
+    fmt.Fprintf(&written, " %s %d", operations[operator].Symbol, e.operands[i + 1])
   }
-  return &operandList
+  return written.String()
 }
 
-const (
-  Addition Operator = iota
-  Product
-  Concatenation
-)
+// Applies the sequence to the operands, left to right as the puzzle does, without precedence
+func (e Equation) Evaluate(sequence Sequence) int {
+  value := e.operands[0]
+  for i, operator := range sequence {
+    value = operations[operator].Apply(value, e.operands[i + 1])
+  }
+  return value
+}
 
-type Operation struct {
-  operator Operator
-  apply func(int, int) int
+// Every sequence of the given operators that makes the operands give the result. It works backwards from the result,
+// undoing the last operand with each operator that allows it, so whole branches are dropped as soon as a product
+// doesn't divide or a concatenation doesn't match the digits
+func (e Equation) Solve(operators []Operator) []Sequence {
+  solutions := []Sequence{}
+  sequence := make(Sequence, len(e.operands) - 1)
+  var undo func(last int, result int)
+  undo = func(last int, result int) {
+    if last == 0 {
+      if result == e.operands[0] { solutions = append(solutions, append(Sequence{}, sequence...)) }
+      return
+    }
+    for _, operator := range operators {
+      left, isPossible := operations[operator].Undo(result, e.operands[last])
+      if !isPossible { continue }
+      sequence[last - 1] = operator
+      undo(last - 1, left)
+    }
+  }
+  undo(len(e.operands) - 1, e.result)
+  return solutions
 }
 
-type Equation struct {
-  result int
-  operands *OperandList
+func init() {
+  registry.Register(constants.Seven, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  equations []Equation
+  // How many operations of the list part 2 tries
+  operators int
+  // The sequences solving each equation, by part, kept for Extras
+  solved map[constants.PartIndex][][]Sequence
+}
+
+// The ways an equation can be solved in the part, if any
+type EquationExtras struct {
+  Equation int `json:"equation"`
+  Result int `json:"result"`
+  Solutions []string `json:"solutions,omitempty"`
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Seven, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Seven, ver, err))
+func (s *solver) Params() []registry.Param {
+  return []registry.Param{
+    {Name: "operators", Default: 3, Min: 1, Usage: "How many operators part 2 tries: 3 for +, * and ||, 4 to add -, and 5 to add ^ too"},
   }
-  equations := getEquations(&lines)
-  part1Operations := []Operation{
-    {Addition, add},
-    {Product, multiply},
+}
+
+func (s *solver) SetParam(name string, value int) {
+  if name == "operators" { s.operators = value }
+}
+
+func (s *solver) Parse(lines []string) error {
+  var err error
+  s.equations, err = getEquations(lines)
+  return err
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(s.run(constants.Part1, []Operator{Addition, Product})), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  if s.operators < 1 || s.operators > len(operations) {
+    return registry.Answer{}, errs.Malformed("operators must be between 1 and %d, got %d", len(operations), s.operators)
   }
-  part2Operations := []Operation{
-    {Addition, add},
-    {Product, multiply},
-    {Concatenation, concatenate},
+  operators := make([]Operator, s.operators)
+  for i := range operators {
+    operators[i] = Operator(i)
   }
-  part1Result := solve(equations, &part1Operations)
-  part2Result := solve(equations, &part2Operations)
-  fmt.Printf("Part 1 result: %d\n", part1Result)
-  fmt.Printf("Part 2 result: %d\n", part2Result)
+  return registry.Int(s.run(constants.Part2, operators)), nil
 }
 
-func solve(equations *[]Equation, operations *[]Operation) int {
-  sum := 0
-  for i, equation := range *equations {
-    if isDebug { fmt.Printf("Processing equation %d (expected %d)\n", i, equation.result) }
-    if isValid(equation.operands.first, operations, equation.result, 0) {
-      if isDebug { fmt.Printf("Equation %d is valid\n\n", i) }
-      sum += equation.result
-      continue
+func (s *solver) Extras(part constants.PartIndex) any {
+  extras := make([]EquationExtras, len(s.equations))
+  for i, equation := range s.equations {
+    solutions := make([]string, len(s.solved[part][i]))
+    for j, sequence := range s.solved[part][i] {
+      solutions[j] = equation.Format(sequence)
     }
-    if isDebug { fmt.Printf("Equation %d is NOT valid\n\n", i) }
+    extras[i] = EquationExtras{i + 1, equation.result, solutions}
   }
-  return sum
+  return extras
 }
 
-func isValid(operand *OperandNode, operations *[]Operation, expected int, soFar int) bool {
-  if isDebug { fmt.Printf("Processing operand %d for operations %+v. Next -> %v | So far: %d\n", operand.value, operations, operand.next, soFar) }
-  for _, operation := range *operations {
-    if operand.next == nil {
-      if operation.apply(soFar, operand.value) == expected { return true }
-      continue
-    } else {
-      if isValid(operand.next, operations, expected, operation.apply(soFar, operand.value)) { return true }
+// Sum of the results of the equations that some sequence of the operators solves
+func (s *solver) run(part constants.PartIndex, operators []Operator) int {
+  sum := 0
+  solved := make([][]Sequence, len(s.equations))
+  for i, equation := range s.equations {
+    solved[i] = equation.Solve(operators)
+    if traceEquations.On() {
+      traceEquations.Printf("Equation %d (expected %d): %d solutions\n", i + 1, equation.result, len(solved[i]))
+      for _, sequence := range solved[i] {
+        // Evaluated forwards again, as a check of the backward search
+        traceEquations.Printf("  %s = %d\n", equation.Format(sequence), equation.Evaluate(sequence))
+      }
     }
+    if len(solved[i]) > 0 { sum += equation.result }
   }
-  return false
+  if s.solved == nil { s.solved = make(map[constants.PartIndex][][]Sequence) }
+  s.solved[part] = solved
+  return sum
 }
 
 func add(n1 int, n2 int) int {
   return n1 + n2
 }
 
+func undoAdd(result int, right int) (int, bool) {
+  return result - right, true
+}
+
+func subtract(n1 int, n2 int) int {
+  return n1 - n2
+}
+
+func undoSubtract(result int, right int) (int, bool) {
+  return result + right, true
+}
+
 func multiply(n1 int, n2 int) int {
   return n1 * n2
 }
 
+// Undoes a product. A product by 0 would fit any left operand, but the operands are all positive
+func divide(result int, right int) (int, bool) {
+  if right == 0 || result % right != 0 { return 0, false }
+  return result / right, true
+}
+
 func concatenate(n1 int, n2 int) int {
   n1str := strconv.Itoa(n1)
   n2str := strconv.Itoa(n2)
@@ -122,19 +218,46 @@ func concatenate(n1 int, n2 int) int {
   return result
 }
 
-func getEquations(lines *[]string) *[]Equation {
-  equations := make([]Equation, len(*lines))
-  for i, line := range *lines {
+// Undoes a concatenation, which is only possible when the result ends with the digits of the right operand
+func truncate(result int, right int) (int, bool) {
+  if result < right || right < 0 { return 0, false }
+  shift := int(math.Pow10(len(strconv.Itoa(right))))
+  if (result - right) % shift != 0 { return 0, false }
+  return (result - right) / shift, true
+}
+
+func power(n1 int, n2 int) int {
+  result := 1
+  for range n2 {
+    result *= n1
+  }
+  return result
+}
+
+// Undoes a power, which is only possible when the result has an exact root. Like for products, a power of 0 is left
+// out, since it would fit any left operand
+func root(result int, right int) (int, bool) {
+  if right < 1 || result < 0 { return 0, false }
+  left := int(math.Round(math.Pow(float64(result), 1 / float64(right))))
+  if power(left, right) != result { return 0, false }
+  return left, true
+}
+
+func getEquations(lines []string) ([]Equation, error) {
+  equations := make([]Equation, len(lines))
+  for i, line := range lines {
     components := strings.Split(line, ": ")
-    result, _ := strconv.Atoi(components[0])
+    if len(components) != 2 { return nil, errs.Malformed("line %d should be a result and its operands separated by ': ': %q", i + 1, line) }
+    result, err := strconv.Atoi(components[0])
+    if err != nil { return nil, errs.Malformed("line %d: %v", i + 1, err) }
     strOps := strings.Split(components[1], " ")
     operands := make([]int, len(strOps))
     for j, strOp := range strOps {
-      op, _ := strconv.Atoi(strOp)
+      op, err := strconv.Atoi(strOp)
+      if err != nil { return nil, errs.Malformed("line %d: %v", i + 1, err) }
       operands[j] = op
     }
-    equations[i] = Equation{result, newOperandList(&operands)}
+    equations[i] = Equation{result, operands}
   }
-  return &equations
+  return equations, nil
 }
-
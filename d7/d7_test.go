@@ -0,0 +1,55 @@
+package d7_test
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/d7"
+	"aoc2k24/registry"
+	"slices"
+	"testing"
+)
+
+// A shift left, registered after the built-in operations as the 6th one
+var shiftLeft = d7.RegisterOperation(d7.Operation{
+  Symbol: "<<",
+  Apply: func(left int, right int) int { return left << right },
+  Undo: func(result int, right int) (int, bool) {
+    if result % (1 << right) != 0 { return 0, false }
+    return result >> right, true
+  },
+})
+
+// Solves part 2 of the equations with the given number of operators, and returns the solutions of each equation
+func solutions(t *testing.T, lines []string, operators int) [][]string {
+  t.Helper()
+  entry, _ := registry.Get(constants.Seven)
+  solver := entry.New()
+  err := registry.Configure(solver, map[string]int{"operators": operators})
+  if err != nil { t.Fatal(err) }
+  err = solver.Parse(lines)
+  if err != nil { t.Fatal(err) }
+  _, err = solver.Part2()
+  if err != nil { t.Fatal(err) }
+  solved := [][]string{}
+  for _, extras := range registry.Extras(solver, constants.Part2).([]d7.EquationExtras) {
+    solved = append(solved, extras.Solutions)
+  }
+  return solved
+}
+
+func TestSequences(t *testing.T) {
+  lines := []string{"3267: 81 40 27", "7290: 6 8 6 15", "83: 17 5", "5: 10 5", "1000: 10 3", "40: 5 3"}
+  expected := [][]string{{"81 * 40 + 27", "81 + 40 * 27"}, {"6 * 8 || 6 * 15"}, nil, nil, nil, nil}
+  solved := solutions(t, lines, 3)
+  if !slices.EqualFunc(solved, expected, slices.Equal) { t.Errorf("got %q, expected %q", solved, expected) }
+
+  // Each extra operator solves one more equation
+  expected[3] = []string{"10 - 5"}
+  solved = solutions(t, lines, 4)
+  if !slices.EqualFunc(solved, expected, slices.Equal) { t.Errorf("with -, got %q, expected %q", solved, expected) }
+  expected[4] = []string{"10 ^ 3"}
+  solved = solutions(t, lines, 5)
+  if !slices.EqualFunc(solved, expected, slices.Equal) { t.Errorf("with ^, got %q, expected %q", solved, expected) }
+  expected[5] = []string{"5 << 3"}
+  solved = solutions(t, lines, int(shiftLeft) + 1)
+  if !slices.EqualFunc(solved, expected, slices.Equal) { t.Errorf("with <<, got %q, expected %q", solved, expected) }
+}
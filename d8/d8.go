@@ -2,143 +2,106 @@ package d8
 
 import (
 	"aoc2k24/constants"
-	"fmt"
-  "aoc2k24/io"
+	"aoc2k24/grid"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 )
 
-const isDebugP1 = false
-const isDebugP2 = false
+var (
+  tracePart1 = trace.New(constants.Eight, "part1", trace.Debug, "Antinodes computed in part 1")
+  tracePart2 = trace.New(constants.Eight, "part2", trace.Debug, "Antinodes computed in part 2, with resonant harmonics")
+)
 
 type AntennaMap struct {
-  antennae map[rune][]int
-  width int
-  height int
+  field *grid.Grid[rune]
+  antennae map[rune][]grid.Coord
 }
 
-func newAntennaMap(lines *[]string) *AntennaMap {
-  am := AntennaMap{make(map[rune][]int), len((*lines)[0]), len(*lines)}
-  for y, line := range *lines {
-    for x, char := range line {
-      if char == '.' { continue }
-      i := y * am.width + x
-      _, exists := am.antennae[rune(char)]; if exists {
-        am.antennae[rune(char)] = append(am.antennae[rune(char)], i)
-        continue
-      }
-      am.antennae[rune(char)] = []int{i}
-    }
+func newAntennaMap(field *grid.Grid[rune]) *AntennaMap {
+  am := AntennaMap{field, make(map[rune][]grid.Coord)}
+  for c, char := range field.All() {
+    if char == '.' { continue }
+    am.antennae[char] = append(am.antennae[char], c)
   }
   return &am
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Eight, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Eight, ver, err))
-  }
-  am := newAntennaMap(&lines)
-  antinodeCount := solvePart1(am)
-  antinodeCount2 := solvePart2(am)
-  fmt.Printf("Unique antinode locations (part 1): %d\n", antinodeCount)
-  fmt.Printf("Unique antinode locations (part 2): %d\n", antinodeCount2)
+func init() {
+  registry.Register(constants.Eight, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  am *AntennaMap
+}
+
+func (s *solver) Parse(lines []string) error {
+  field, err := grid.Parse(lines)
+  if err != nil { return err }
+  s.am = newAntennaMap(field)
+  return nil
+}
+
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(solvePart1(s.am)), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Int(solvePart2(s.am)), nil
 }
 
+// With resonant harmonics, antinodes repeat at every multiple of the distance between both antennae, in both
+// directions and including the antennae themselves, until they fall outside the field
 func solvePart2(am *AntennaMap) int {
-  antinodes := make(map[int]struct{})
-  for antennaType := range am.antennae {
-    if isDebugP2 { fmt.Printf("Processing antennae of type '%c'\n", antennaType) }
-    for _, position := range am.antennae[antennaType] {
-      _, exists := antinodes[position]; if !exists { antinodes[position] = struct{}{} }
-      for _, otherPosition := range am.antennae[antennaType] {
+  antinodes := make(map[grid.Coord]struct{})
+  for antennaType, positions := range am.antennae {
+    if tracePart2.On() { tracePart2.Printf("Processing antennae of type '%c'\n", antennaType) }
+    for _, position := range positions {
+      for _, otherPosition := range positions {
         if position == otherPosition { continue }
-        _, exists := antinodes[otherPosition]; if !exists { antinodes[otherPosition] = struct{}{} }
-        x := position % am.width
-        y := position / am.width
-        otherX := otherPosition % am.width
-        otherY := otherPosition / am.width
-        if isDebugP2 { fmt.Printf("Computing antinodes for antennae '%c' in positions %d (x %d, y %d) and %d (x %d, y %d)\n", antennaType, position, x, y, otherPosition, otherX, otherY) }
-        xDiff := x - otherX; if xDiff < 0 { xDiff = -xDiff }
-        yDiff := y - otherY; if yDiff < 0 { yDiff = -yDiff }
-        addAntinodes(&antinodes, x, y, otherX, otherY, xDiff, yDiff, am.width, am.height)
+        if tracePart2.On() { tracePart2.Printf("Computing antinodes for antennae '%c' in positions %s and %s\n", antennaType, position, otherPosition) }
+        diff := position.Sub(otherPosition)
+        for antinode := position; am.field.Contains(antinode); antinode = antinode.Add(diff) {
+          if tracePart2.On() { tracePart2.Printf("Antinode at %s\n", antinode) }
+          antinodes[antinode] = struct{}{}
+        }
+        for antinode := otherPosition; am.field.Contains(antinode); antinode = antinode.Sub(diff) {
+          if tracePart2.On() { tracePart2.Printf("Antinode at %s\n", antinode) }
+          antinodes[antinode] = struct{}{}
+        }
       }
-      if isDebugP2 { fmt.Print("\n") }
+      if tracePart2.On() { tracePart2.Print("\n") }
     }
-    if isDebugP2 { fmt.Print("\n") }
+    if tracePart2.On() { tracePart2.Print("\n") }
   }
   return len(antinodes)
 }
 
-func addAntinodes(antinodes *map[int]struct{}, x, y, otherX, otherY, xDiff, yDiff, width, height int) {
-  if (x < 0 || x >= width || y < 0 || y >= height) && (otherX < 0 || otherX >= width || otherY < 0 || otherY >= height) {
-    return
-  }
-  antinode1X := x - xDiff
-  antinode2X := otherX + xDiff
-  if otherX < x {
-    antinode1X = x + xDiff
-    antinode2X = otherX - xDiff
-  }
-  antinode1Y := y - yDiff
-  antinode2Y := otherY + yDiff
-  if otherY < y {
-    antinode1Y = y + yDiff
-    antinode2Y = otherY - yDiff
-  }
-  if isDebugP2 { fmt.Printf("First antinode coordinates: x %d, y %d. Second antinode coordinates: x %d, y %d\n", antinode1X, antinode1Y, antinode2X, antinode2Y) }
-  if antinode1X >= 0 && antinode1X < width && antinode1Y >= 0 && antinode1Y < height {
-    if isDebugP2 { fmt.Print("Antinode 1 position is inside field. Adding to map if it doesn't already exist\n") }
-    antinodePos := antinode1Y * width + antinode1X
-    _, exists := (*antinodes)[antinodePos]; if !exists { (*antinodes)[antinodePos] = struct{}{} }
-  }
-  if antinode2X >= 0 && antinode2X < width && antinode2Y >= 0 && antinode2Y < height {
-    if isDebugP2 { fmt.Print("Antinode 2 position is inside field. Adding to map if it doesn't already exist\n") }
-    antinodePos := antinode2Y * width + antinode2X
-    _, exists := (*antinodes)[antinodePos]; if !exists { (*antinodes)[antinodePos] = struct{}{} }
-  }
-  addAntinodes(antinodes, antinode1X, antinode1Y, antinode2X, antinode2Y, xDiff, yDiff, width, height)
-}
-
+// Every pair of antennae of the same type has two antinodes, each one as far from the closest antenna as the
+// antennae are from each other
 func solvePart1(am *AntennaMap) int {
-  antinodes := make(map[int]struct{})
-  for antennaType := range am.antennae {
-    if isDebugP1 { fmt.Printf("Processing antennae of type '%c'\n", antennaType) }
-    for _, position := range am.antennae[antennaType] {
-      for _, otherPosition := range am.antennae[antennaType] {
+  antinodes := make(map[grid.Coord]struct{})
+  for antennaType, positions := range am.antennae {
+    if tracePart1.On() { tracePart1.Printf("Processing antennae of type '%c'\n", antennaType) }
+    for _, position := range positions {
+      for _, otherPosition := range positions {
         if position == otherPosition { continue }
-        x := position % am.width
-        y := position / am.width
-        otherX := otherPosition % am.width
-        otherY := otherPosition / am.width
-        if isDebugP1 { fmt.Printf("Computing antinodes for antennae '%c' in positions %d (x %d, y %d) and %d (x %d, y %d)\n", antennaType, position, x, y, otherPosition, otherX, otherY) }
-        xDiff := x - otherX; if xDiff < 0 { xDiff = -xDiff }
-        yDiff := y - otherY; if yDiff < 0 { yDiff = -yDiff }
-        antinode1X := x - xDiff
-        antinode2X := otherX + xDiff
-        if otherX < x {
-          antinode1X = x + xDiff
-          antinode2X = otherX - xDiff
-        }
-        antinode1Y := y - yDiff
-        antinode2Y := otherY + yDiff
-        if otherY < y {
-          antinode1Y = y + yDiff
-          antinode2Y = otherY - yDiff
-        }
-        if isDebugP1 { fmt.Printf("First antinode coordinates: x %d, y %d. Second antinode coordinates: x %d, y %d\n", antinode1X, antinode1Y, antinode2X, antinode2Y) }
-        if antinode1X >= 0 && antinode1X < am.width && antinode1Y >= 0 && antinode1Y < am.height {
-          if isDebugP1 { fmt.Print("Antinode 1 position is inside field. Adding to map if it doesn't already exist\n") }
-          antinodePos := antinode1Y * am.width + antinode1X
-          _, exists := antinodes[antinodePos]; if !exists { antinodes[antinodePos] = struct{}{} }
+        if tracePart1.On() { tracePart1.Printf("Computing antinodes for antennae '%c' in positions %s and %s\n", antennaType, position, otherPosition) }
+        diff := position.Sub(otherPosition)
+        antinode1 := position.Add(diff)
+        antinode2 := otherPosition.Sub(diff)
+        if tracePart1.On() { tracePart1.Printf("First antinode coordinates: %s. Second antinode coordinates: %s\n", antinode1, antinode2) }
+        if am.field.Contains(antinode1) {
+          if tracePart1.On() { tracePart1.Print("Antinode 1 position is inside field. Adding to map if it doesn't already exist\n") }
+          antinodes[antinode1] = struct{}{}
         }
-        if antinode2X >= 0 && antinode2X < am.width && antinode2Y >= 0 && antinode2Y < am.height {
-          if isDebugP1 { fmt.Print("Antinode 2 position is inside field. Adding to map if it doesn't already exist\n") }
-          antinodePos := antinode2Y * am.width + antinode2X
-          _, exists := antinodes[antinodePos]; if !exists { antinodes[antinodePos] = struct{}{} }
+        if am.field.Contains(antinode2) {
+          if tracePart1.On() { tracePart1.Print("Antinode 2 position is inside field. Adding to map if it doesn't already exist\n") }
+          antinodes[antinode2] = struct{}{}
         }
       }
-      if isDebugP1 { fmt.Print("\n") }
+      if tracePart1.On() { tracePart1.Print("\n") }
     }
-    if isDebugP1 { fmt.Print("\n") }
+    if tracePart1.On() { tracePart1.Print("\n") }
   }
   return len(antinodes)
 }
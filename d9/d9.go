@@ -2,44 +2,60 @@ package d9
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/io"
-	"fmt"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
 	"strconv"
 )
 
-const isDebug = false
+var traceBlocks = trace.New(constants.Nine, "blocks", trace.Debug, "Disk blocks before and after compacting them")
 
 type Sparse []int
 
 func (s Sparse) Print() {
-  fmt.Print("\n")
+  traceBlocks.Print("\n")
   for _, block := range s {
     if block == -1 {
-      fmt.Print(".")
+      traceBlocks.Print(".")
     } else {
-      fmt.Print(block)
+      traceBlocks.Print(block)
     }
   }
-  fmt.Print("\n")
+  traceBlocks.Print("\n")
 }
 
-func Init(ver constants.VersionIndex) {
-  lines, err := io.GetLinesFor(constants.Nine, ver)
-  if (err != nil) {
-    panic(fmt.Sprintf("Error loading file for day %d, version %d: %v", constants.Nine, ver, err))
-  }
+func init() {
+  registry.Register(constants.Nine, func() registry.Solver { return &solver{} })
+}
+
+type solver struct {
+  dense string
+}
+
+func (s *solver) Parse(lines []string) error {
   // Puzzle entry has only 1 line
-  checksum := solvePart1(getSparse(&lines[0]))
-  checksum2 := solvePart2(getSparse(&lines[0]))
-  fmt.Printf("Checksum Part 1: %d\n", checksum)
-  fmt.Printf("Checksum Part 2: %d\n", checksum2)
+  if len(lines) == 0 { return errs.Malformed("disk map is empty") }
+  for i, char := range lines[0] {
+    if char < '0' || char > '9' { return errs.Malformed("disk map has non digit '%c' at position %d", char, i) }
+  }
+  s.dense = lines[0]
+  return nil
+}
+
+// Each part compacts its own copy of the blocks, since compaction happens in place
+func (s *solver) Part1() (registry.Answer, error) {
+  return registry.Int(solvePart1(getSparse(&s.dense))), nil
+}
+
+func (s *solver) Part2() (registry.Answer, error) {
+  return registry.Int(solvePart2(getSparse(&s.dense))), nil
 }
 
 func solvePart2(blocks *Sparse) int {
   checksum := 0
-  if isDebug { fmt.Print("\nBefore defragged consolidation of free blocks: "); blocks.Print() }
+  if traceBlocks.On() { traceBlocks.Print("\nBefore defragged consolidation of free blocks: "); blocks.Print() }
   defragConsolidateFreeBlocks(blocks)
-  if isDebug { fmt.Print("\nAfter defragged consolidation of free blocks: "); blocks.Print() }
+  if traceBlocks.On() { traceBlocks.Print("\nAfter defragged consolidation of free blocks: "); blocks.Print() }
   for i, block := range *blocks {
     // Skip empty blocks
     if block == -1 { continue }
@@ -54,14 +70,14 @@ func defragConsolidateFreeBlocks(blocks *Sparse) {
     if (*blocks)[i] == -1 { continue }
     start := getStartOfFile(blocks, i)
     length := i - start + 1
-    if isDebug { fmt.Printf("File of length %d found. Starts at %d and ends at %d\n", length, start, start + length - 1) }
+    if traceBlocks.On() { traceBlocks.Printf("File of length %d found. Starts at %d and ends at %d\n", length, start, start + length - 1) }
     freeBlocksStart := getNextFreeBlocks(blocks, start, length)
     // If no free blocks of this size at the left of current file, skip file
     if freeBlocksStart == -1 { 
       i = start
       continue 
     }
-    if isDebug { fmt.Printf("Free space found starting at %d and ending at %d\n", freeBlocksStart, freeBlocksStart + length - 1) }
+    if traceBlocks.On() { traceBlocks.Printf("Free space found starting at %d and ending at %d\n", freeBlocksStart, freeBlocksStart + length - 1) }
     for j := range length {
       (*blocks)[freeBlocksStart + j] = (*blocks)[start + j]
       (*blocks)[start + j] = -1
@@ -97,9 +113,9 @@ func getStartOfFile(blocks *Sparse, end int) int {
 }
 
 func solvePart1(blocks *Sparse) int {
-  if isDebug { fmt.Print("\nBefore consolidation of free blocks: "); blocks.Print() }
+  if traceBlocks.On() { traceBlocks.Print("\nBefore consolidation of free blocks: "); blocks.Print() }
   consolidateFreeBlocks(blocks)
-  if isDebug { fmt.Print("\nAfter consolidation of free blocks: "); blocks.Print(); fmt.Print("\n") }
+  if traceBlocks.On() { traceBlocks.Print("\nAfter consolidation of free blocks: "); blocks.Print(); traceBlocks.Print("\n") }
   checksum := 0
   for i, block := range *blocks {
     // If we reached the empty slots, process is over
@@ -136,15 +152,15 @@ func getSparse(dense *string) *Sparse {
   blocks := Sparse(make([]int, 0))
   isFile := false
   fileId := 0
-  if isDebug { fmt.Printf("Dense input: %s\n", *dense) }
+  if traceBlocks.On() { traceBlocks.Printf("Dense input: %s\n", *dense) }
   for _, char := range *dense {
-    if isDebug { fmt.Printf("Analyzing dense unit '%c'\n", char) }
+    if traceBlocks.On() { traceBlocks.Printf("Analyzing dense unit '%c'\n", char) }
     isFile = !isFile
     if char == '0' { continue }
-    if isDebug { fmt.Printf("Is this file? %v\n", isFile) }
+    if traceBlocks.On() { traceBlocks.Printf("Is this file? %v\n", isFile) }
     length, _ := strconv.Atoi(string(char))
     id := fileId; if !isFile { id = -1 }
-    if isDebug { fmt.Printf("Adding %d blocks of id %d\n", length, id) }
+    if traceBlocks.On() { traceBlocks.Printf("Adding %d blocks of id %d\n", length, id) }
     for range length {
       blocks = append(blocks, id)
     }
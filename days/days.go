@@ -0,0 +1,24 @@
+// Imports every day package so that each one registers its solver. Adding a new day only requires a new import here
+package days
+
+import (
+	_ "aoc2k24/d1"
+	_ "aoc2k24/d2"
+	_ "aoc2k24/d3"
+	_ "aoc2k24/d4"
+	_ "aoc2k24/d5"
+	_ "aoc2k24/d6"
+	_ "aoc2k24/d7"
+	_ "aoc2k24/d8"
+	_ "aoc2k24/d9"
+	_ "aoc2k24/d10"
+	_ "aoc2k24/d11"
+	_ "aoc2k24/d12"
+	_ "aoc2k24/d13"
+	_ "aoc2k24/d14"
+	_ "aoc2k24/d15"
+	_ "aoc2k24/d16"
+	_ "aoc2k24/d17"
+	_ "aoc2k24/d18"
+	_ "aoc2k24/d19"
+)
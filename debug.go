@@ -0,0 +1,54 @@
+package main
+
+import (
+	"aoc2k24/d17"
+	"aoc2k24/errs"
+	"aoc2k24/io"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Steps through the program of a day 17 input, reading debugger commands from stdin or from a script
+func runDebug(args []string) int {
+  flags := flag.NewFlagSet("debug", flag.ExitOnError)
+  versionParam := flags.String("v", "0", fmt.Sprintf("The version of day 17 to debug, by number or by its name in %s", io.ManifestFile))
+  inputParam := flags.String("input", "", "Debug the program in this file instead of an input of day 17")
+  inputsParam := flags.String("inputs", "", io.InputsFlagUsage("Directory holding the puzzle inputs"))
+  scriptParam := flags.String("script", "", "Read the commands from this file instead of stdin")
+  flags.Parse(args)
+
+  if *inputParam == io.Stdin && *scriptParam == "" {
+    fmt.Fprintln(os.Stderr, "Error: stdin can't hold both the program and the commands, give the commands with -script")
+    return errs.ExitUsage
+  }
+  io.SetInputRoot(*inputsParam)
+  registers, program, exitCode := loadProgram(*versionParam, *inputParam)
+  if exitCode != errs.ExitOK { return exitCode }
+  debugger := d17.NewDebugger(registers, program, os.Stdout)
+  commands := os.Stdin
+  if *scriptParam != "" {
+    script, err := os.Open(*scriptParam)
+    if err != nil {
+      fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+      return errs.ExitUsage
+    }
+    defer script.Close()
+    commands = script
+  } else if isTerminal(os.Stdin) {
+    fmt.Println("Day 17 debugger, help lists the commands")
+    debugger.Prompt = "(d17) "
+  }
+  err := debugger.Run(commands)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitUnknown
+  }
+  return errs.ExitOK
+}
+
+// Whether the file is a terminal rather than a pipe or a regular file, i.e. someone is typing into it
+func isTerminal(file *os.File) bool {
+  info, err := file.Stat()
+  return err == nil && info.Mode() & os.ModeCharDevice != 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/d17"
+	"aoc2k24/errs"
+	"aoc2k24/io"
+	"aoc2k24/selector"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Disassembles the program of a day 17 input, which is otherwise only a list of numbers
+func runDisasm(args []string) int {
+  flags := flag.NewFlagSet("disasm", flag.ExitOnError)
+  versionParam := flags.String("v", "0", fmt.Sprintf("The version of day 17 to disassemble, by number or by its name in %s", io.ManifestFile))
+  inputParam := flags.String("input", "", "Disassemble the program in this file, or in stdin if -, instead of an input of day 17")
+  inputsParam := flags.String("inputs", "", io.InputsFlagUsage("Directory holding the puzzle inputs"))
+  pseudoParam := flags.Bool("pseudo", false, "Also lift the program to pseudo-code")
+  symbolicParam := flags.Bool("symbolic", false, "Also give every output of a quine as a formula of the bits of a, the initial value of register A")
+  flags.Parse(args)
+
+  io.SetInputRoot(*inputsParam)
+  registers, program, exitCode := loadProgram(*versionParam, *inputParam)
+  if exitCode != errs.ExitOK { return exitCode }
+  fmt.Printf("Registers: A=%d B=%d C=%d\n\n", registers.A, registers.B, registers.C)
+  fmt.Print(d17.Disassemble(program))
+  if *pseudoParam {
+    fmt.Println()
+    fmt.Print(d17.Pseudocode(program))
+  }
+  if *symbolicParam {
+    formulas, err := d17.Formulas(registers, program, len(program))
+    if err != nil {
+      fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+      return errs.ExitUnknown
+    }
+    fmt.Println()
+    for i, formula := range formulas {
+      fmt.Printf("out[%d] = %s\n", i, formula)
+    }
+  }
+  return errs.ExitOK
+}
+
+// Reads the registers and the program from a version of day 17, or from the file at inputPath if given. Errors are
+// reported here, and the exit code for them returned
+func loadProgram(versionSpec string, inputPath string) (d17.Registers, d17.Program, int) {
+  manifest, err := io.LoadManifest()
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return d17.Registers{}, nil, errs.ExitCode(err)
+  }
+  inputs, err := selector.ResolveVersions(manifest, []constants.DayIndex{constants.Seventeen}, versionSpec)
+  if err == nil && inputPath != "" {
+    inputs, err = selector.UsePath(inputs, inputPath)
+  }
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return d17.Registers{}, nil, errs.ExitUsage
+  }
+  lines, err := io.GetLines(inputs[0])
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return d17.Registers{}, nil, errs.ExitCode(err)
+  }
+  registers, program, err := d17.ParseInput(lines)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return d17.Registers{}, nil, errs.ExitCode(err)
+  }
+  return registers, program, errs.ExitOK
+}
@@ -0,0 +1,91 @@
+package errs
+
+import (
+	"aoc2k24/constants"
+	"errors"
+	"fmt"
+)
+
+var (
+  ErrInputNotFound = errors.New("input not found")
+  ErrMalformedInput = errors.New("malformed input")
+  ErrDayNotImplemented = errors.New("day not implemented")
+  ErrPartUnsolvable = errors.New("part unsolvable")
+  ErrWrongAnswer = errors.New("wrong answer")
+  ErrRequestFailed = errors.New("request to the Advent of Code site failed")
+  ErrPanicked = errors.New("solver panicked")
+)
+
+// Exit codes, one per kind of error so that scripts can tell them apart. 2 is left to the flag package for bad usage
+const (
+  ExitOK = 0
+  ExitUnknown = 1
+  ExitUsage = 2
+  ExitInputNotFound = 3
+  ExitMalformedInput = 4
+  ExitDayNotImplemented = 5
+  ExitPartUnsolvable = 6
+  ExitWrongAnswer = 7
+  ExitRequestFailed = 8
+  ExitPanicked = 9
+)
+
+// Wraps an error with the day, version and (if any) part it happened in. Part is 0 while loading or parsing the input
+type DayError struct {
+  Day constants.DayIndex
+  Ver constants.VersionIndex
+  Part constants.PartIndex
+  Err error
+}
+
+func (e *DayError) Error() string {
+  if e.Part == 0 {
+    return fmt.Sprintf("day %d, version %d: %v", e.Day, e.Ver, e.Err)
+  }
+  return fmt.Sprintf("day %d, version %d, part %d: %v", e.Day, e.Ver, e.Part, e.Err)
+}
+
+func (e *DayError) Unwrap() error {
+  return e.Err
+}
+
+func Malformed(format string, args ...any) error {
+  return fmt.Errorf("%w: %s", ErrMalformedInput, fmt.Sprintf(format, args...))
+}
+
+func Unsolvable(format string, args ...any) error {
+  return fmt.Errorf("%w: %s", ErrPartUnsolvable, fmt.Sprintf(format, args...))
+}
+
+// A panic recovered while solving. Its stack is left out, since it would flood the reports: see selector.RunDay
+func Panicked(value any) error {
+  return fmt.Errorf("%w: %v", ErrPanicked, value)
+}
+
+// An answer that differs from the one the manifest expects
+func WrongAnswer(got string, expected string) error {
+  return fmt.Errorf("%w: got %s, expected %s", ErrWrongAnswer, got, expected)
+}
+
+func ExitCode(err error) int {
+  switch {
+  case err == nil:
+    return ExitOK
+  case errors.Is(err, ErrInputNotFound):
+    return ExitInputNotFound
+  case errors.Is(err, ErrMalformedInput):
+    return ExitMalformedInput
+  case errors.Is(err, ErrDayNotImplemented):
+    return ExitDayNotImplemented
+  case errors.Is(err, ErrPartUnsolvable):
+    return ExitPartUnsolvable
+  case errors.Is(err, ErrWrongAnswer):
+    return ExitWrongAnswer
+  case errors.Is(err, ErrRequestFailed):
+    return ExitRequestFailed
+  case errors.Is(err, ErrPanicked):
+    return ExitPanicked
+  default:
+    return ExitUnknown
+  }
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"aoc2k24/errs"
+	"aoc2k24/fetch"
+	"aoc2k24/io"
+	"aoc2k24/selector"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Downloads the full inputs of the selected days that aren't in the inputs directory yet
+func runFetch(args []string) int {
+  flags := flag.NewFlagSet("fetch", flag.ExitOnError)
+  dayParam := flags.String("day", "all", "The days to download: all (every registered day), a single day (5), a range (1-10) or a comma separated list (1,3,5-7)")
+  inputsParam := flags.String("inputs", "", io.InputsFlagUsage("Directory to download to"))
+  sessionParam := flags.String("session", os.Getenv(fetch.SessionEnvVar), fmt.Sprintf("Session token, the value of the session cookie of the site. Defaults to $%s", fetch.SessionEnvVar))
+  urlParam := flags.String("url", fetch.DefaultBaseURL, "Base URL of the site, to download from a local stand-in instead")
+  flags.Parse(args)
+
+  days, err := selector.ParseDays(*dayParam)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitUsage
+  }
+  io.SetInputRoot(*inputsParam)
+  dir, err := io.InputsDir()
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitCode(err)
+  }
+  client := fetch.NewClient(*sessionParam)
+  client.BaseURL = *urlParam
+  for _, day := range days {
+    path, isDownloaded, err := client.Fetch(day, dir)
+    // The remaining days would most likely fail the same way (bad token, rate limited), so it stops at the first error
+    if err != nil {
+      fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+      return errs.ExitCode(err)
+    }
+    if isDownloaded {
+      fmt.Printf("Day %d: downloaded to %s\n", day, path)
+    } else {
+      fmt.Printf("Day %d: already in %s\n", day, path)
+    }
+  }
+  return errs.ExitOK
+}
@@ -0,0 +1,117 @@
+// Downloads puzzle inputs from the Advent of Code site into the inputs directory. Inputs differ by user, so every
+// request carries the session cookie of a logged in browser. Downloaded inputs are cached and never requested again
+package fetch
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	aocio "aoc2k24/io"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+  DefaultBaseURL = "https://adventofcode.com"
+  Year = 2024
+  // Environment variable holding the session token, the value of the site's session cookie
+  SessionEnvVar = "AOC2K24_SESSION"
+  // The site asks automated tools to throttle their requests
+  DefaultInterval = 3 * time.Second
+  // The site also asks them to identify themselves
+  userAgent = "aoc2k24 input fetcher (Go net/http)"
+)
+
+type Client struct {
+  BaseURL string
+  Session string
+  // Minimum time between the start of two requests
+  Interval time.Duration
+  HTTP *http.Client
+  mutex sync.Mutex
+  lastRequest time.Time
+}
+
+func NewClient(session string) *Client {
+  return &Client{BaseURL: DefaultBaseURL, Session: session, Interval: DefaultInterval, HTTP: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Downloads the full input of the day into dir as <day>-0.txt, unless the file is already there. Returns the path of
+// the input and whether it was downloaded
+func (c *Client) Fetch(day constants.DayIndex, dir string) (string, bool, error) {
+  path := aocio.FilePath(dir, day, 0)
+  _, err := os.Stat(path)
+  if err == nil { return path, false, nil }
+  if !os.IsNotExist(err) { return "", false, err }
+
+  body, err := c.Get(fmt.Sprintf("/%d/day/%d/input", Year, day))
+  if err != nil { return "", false, fmt.Errorf("day %d: %w", day, err) }
+  err = writeAtomically(path, body)
+  if err != nil { return "", false, err }
+  return path, true, nil
+}
+
+// Sends an authenticated GET request to the site, waiting first if the previous one was too recent. Responses other
+// than 200 are returned as errors wrapping errs.ErrRequestFailed
+func (c *Client) Get(urlPath string) ([]byte, error) {
+  request, err := http.NewRequest(http.MethodGet, c.BaseURL + urlPath, nil)
+  if err != nil { return nil, err }
+  return c.Do(request)
+}
+
+// Like Get, for requests built by the caller (e.g. posting an answer)
+func (c *Client) Do(request *http.Request) ([]byte, error) {
+  if c.Session == "" { return nil, fmt.Errorf("%w: no session token, set $%s or pass -session", errs.ErrRequestFailed, SessionEnvVar) }
+  request.AddCookie(&http.Cookie{Name: "session", Value: c.Session})
+  request.Header.Set("User-Agent", userAgent)
+  c.throttle()
+  response, err := c.HTTP.Do(request)
+  if err != nil { return nil, fmt.Errorf("%w: %v", errs.ErrRequestFailed, err) }
+  defer response.Body.Close()
+  body, err := io.ReadAll(response.Body)
+  if err != nil { return nil, fmt.Errorf("%w: %v", errs.ErrRequestFailed, err) }
+  if response.StatusCode != http.StatusOK { return nil, statusError(request, response, body) }
+  return body, nil
+}
+
+// Sleeps until Interval has passed since the previous request
+func (c *Client) throttle() {
+  c.mutex.Lock()
+  defer c.mutex.Unlock()
+  wait := c.Interval - time.Since(c.lastRequest)
+  if !c.lastRequest.IsZero() && wait > 0 { time.Sleep(wait) }
+  c.lastRequest = time.Now()
+}
+
+func statusError(request *http.Request, response *http.Response, body []byte) error {
+  reason := strings.TrimSpace(string(body))
+  switch response.StatusCode {
+  case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+    reason = "the session token was rejected, it may have expired"
+  case http.StatusNotFound:
+    reason = "not found, the puzzle may not be unlocked yet"
+  case http.StatusTooManyRequests:
+    reason = "rate limited by the site"
+    retryAfter := response.Header.Get("Retry-After")
+    if retryAfter != "" { reason += fmt.Sprintf(", retry after %s seconds", retryAfter) }
+  }
+  return fmt.Errorf("%w: %s %s: %s: %s", errs.ErrRequestFailed, request.Method, request.URL.Path, response.Status, reason)
+}
+
+// Writes to a temporary file first, so an interrupted download can't leave a truncated input that would then be taken
+// as cached
+func writeAtomically(path string, data []byte) error {
+  file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path) + ".*.tmp")
+  if err != nil { return err }
+  _, err = file.Write(data)
+  closeErr := file.Close()
+  if err == nil { err = closeErr }
+  if err == nil { err = os.Rename(file.Name(), path) }
+  if err != nil { os.Remove(file.Name()) }
+  return err
+}
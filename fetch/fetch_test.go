@@ -0,0 +1,131 @@
+package fetch_test
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/fetch"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const session = "test-session"
+
+// Stand-in for the site, serving the input of any day as "input of day N" to requests with the test session cookie
+func newServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    requests.Add(1)
+    cookie, err := r.Cookie("session")
+    if err != nil || cookie.Value != session {
+      http.Error(w, "Puzzle inputs differ by user. Please log in to get your puzzle input.", http.StatusBadRequest)
+      return
+    }
+    if r.Header.Get("User-Agent") == "" { t.Error("request without a User-Agent") }
+    var day int
+    _, err = fmt.Sscanf(r.URL.Path, "/2024/day/%d/input", &day)
+    if err != nil || day > 20 {
+      http.NotFound(w, r)
+      return
+    }
+    fmt.Fprintf(w, "input of day %d\n", day)
+  }))
+  t.Cleanup(server.Close)
+  return server
+}
+
+func newClient(server *httptest.Server, session string) *fetch.Client {
+  client := fetch.NewClient(session)
+  client.BaseURL = server.URL
+  client.Interval = 0
+  return client
+}
+
+func TestFetchDownloads(t *testing.T) {
+  var requests atomic.Int32
+  client := newClient(newServer(t, &requests), session)
+  dir := t.TempDir()
+  path, isDownloaded, err := client.Fetch(5, dir)
+  if err != nil { t.Fatal(err) }
+  if !isDownloaded { t.Error("expected the input to be downloaded") }
+  if path != filepath.Join(dir, "5-0.txt") { t.Errorf("downloaded to %s", path) }
+  content, err := os.ReadFile(path)
+  if err != nil { t.Fatal(err) }
+  if string(content) != "input of day 5\n" { t.Errorf("unexpected content %q", content) }
+}
+
+func TestFetchNeverDownloadsTwice(t *testing.T) {
+  var requests atomic.Int32
+  client := newClient(newServer(t, &requests), session)
+  dir := t.TempDir()
+  cached := filepath.Join(dir, "3-0.txt")
+  err := os.WriteFile(cached, []byte("placed by hand\n"), 0644)
+  if err != nil { t.Fatal(err) }
+
+  for range 2 {
+    _, isDownloaded, err := client.Fetch(3, dir)
+    if err != nil { t.Fatal(err) }
+    if isDownloaded { t.Error("input placed by hand was downloaded again") }
+    _, _, err = client.Fetch(4, dir)
+    if err != nil { t.Fatal(err) }
+  }
+  if requests.Load() != 1 { t.Errorf("expected 1 request, the first download of day 4, got %d", requests.Load()) }
+  content, _ := os.ReadFile(cached)
+  if string(content) != "placed by hand\n" { t.Errorf("input placed by hand was overwritten with %q", content) }
+}
+
+func TestFetchErrors(t *testing.T) {
+  var requests atomic.Int32
+  server := newServer(t, &requests)
+  tests := []struct {
+    name string
+    session string
+    day int
+    requests int32
+  }{
+    {"no session", "", 1, 0},
+    {"rejected session", "expired", 1, 1},
+    {"locked day", session, 25, 1},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      requests.Store(0)
+      dir := t.TempDir()
+      _, _, err := newClient(server, test.session).Fetch(constants.DayIndex(test.day), dir)
+      if !errors.Is(err, errs.ErrRequestFailed) { t.Errorf("expected a failed request, got %v", err) }
+      if requests.Load() != test.requests { t.Errorf("expected %d requests, got %d", test.requests, requests.Load()) }
+      files, _ := os.ReadDir(dir)
+      if len(files) != 0 { t.Errorf("failed download left %d files behind", len(files)) }
+    })
+  }
+}
+
+func TestFetchRateLimited(t *testing.T) {
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Retry-After", "60")
+    w.WriteHeader(http.StatusTooManyRequests)
+  }))
+  defer server.Close()
+  _, _, err := newClient(server, session).Fetch(1, t.TempDir())
+  if !errors.Is(err, errs.ErrRequestFailed) { t.Fatalf("expected a failed request, got %v", err) }
+  if errs.ExitCode(err) != errs.ExitRequestFailed { t.Errorf("unexpected exit code %d", errs.ExitCode(err)) }
+}
+
+func TestFetchThrottles(t *testing.T) {
+  var requests atomic.Int32
+  client := newClient(newServer(t, &requests), session)
+  client.Interval = 100 * time.Millisecond
+  dir := t.TempDir()
+  start := time.Now()
+  for day := range 3 {
+    _, _, err := client.Fetch(constants.DayIndex(day + 1), dir)
+    if err != nil { t.Fatal(err) }
+  }
+  elapsed := time.Since(start)
+  if elapsed < 2 * client.Interval { t.Errorf("3 requests took %s, expected at least %s between each", elapsed, client.Interval) }
+}
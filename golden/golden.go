@@ -0,0 +1,67 @@
+// Golden answer harness. Every input with expected answers in the manifest is run through the selector and its
+// answers compared with the expected ones, so that refactors can't silently change them
+package golden
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/io"
+	"aoc2k24/selector"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Every input in the manifest that has at least one expected answer
+func Load() ([]io.InputInfo, error) {
+  manifest, err := io.LoadManifest()
+  if err != nil { return nil, err }
+  checked := []io.InputInfo{}
+  for _, input := range manifest.All() {
+    if len(input.Expected()) == 0 { continue }
+    checked = append(checked, input)
+  }
+  return checked, nil
+}
+
+func Name(input io.InputInfo) string {
+  return fmt.Sprintf("day%d/%s", input.Day, input.Name)
+}
+
+// Runs every input of the day that has expected answers, each one as a subtest
+func CheckDay(t *testing.T, day constants.DayIndex) {
+  t.Helper()
+  inputs, err := Load()
+  if err != nil { t.Fatal(err) }
+  for _, input := range inputs {
+    if input.Day != day { continue }
+    t.Run(Name(input), func(t *testing.T) { Check(t, input) })
+  }
+}
+
+// Full puzzle inputs (version 0) are skipped with -short, since some of them take several seconds
+func Check(t *testing.T, input io.InputInfo) {
+  t.Helper()
+  if testing.Short() && input.Ver == 0 {
+    t.Skip("full puzzle input skipped in short mode")
+  }
+  expected := input.Expected()
+  results, err := selector.RunDay(input, nil)
+  // A part that can't be solved for this input is fine, as long as its answer isn't being checked
+  var dayErr *errs.DayError
+  if err != nil && (!errors.As(err, &dayErr) || dayErr.Part == 0 || expected[dayErr.Part] != "") {
+    t.Fatal(err)
+  }
+
+  for part, answer := range expected {
+    found := false
+    for _, result := range results {
+      if result.Part != part { continue }
+      found = true
+      if result.Answer.String() != answer {
+        t.Errorf("part %d: got %s, expected %s", part, result.Answer, answer)
+      }
+    }
+    if !found { t.Errorf("part %d: no answer, expected %s", part, answer) }
+  }
+}
@@ -0,0 +1,15 @@
+package golden_test
+
+import (
+	_ "aoc2k24/days"
+	"aoc2k24/golden"
+	"testing"
+)
+
+func TestAnswers(t *testing.T) {
+  inputs, err := golden.Load()
+  if err != nil { t.Fatal(err) }
+  for _, input := range inputs {
+    t.Run(golden.Name(input), func(t *testing.T) { golden.Check(t, input) })
+  }
+}
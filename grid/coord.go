@@ -0,0 +1,82 @@
+package grid
+
+import "fmt"
+
+type Coord struct {
+  X int
+  Y int
+}
+
+func (c Coord) Add(o Coord) Coord {
+  return Coord{c.X + o.X, c.Y + o.Y}
+}
+
+func (c Coord) Sub(o Coord) Coord {
+  return Coord{c.X - o.X, c.Y - o.Y}
+}
+
+func (c Coord) Scale(n int) Coord {
+  return Coord{c.X * n, c.Y * n}
+}
+
+// The neighbouring coordinate in the given direction
+func (c Coord) Step(dir Direction) Coord {
+  return c.Add(dir.Delta())
+}
+
+func (c Coord) Manhattan(o Coord) int {
+  return abs(c.X - o.X) + abs(c.Y - o.Y)
+}
+
+func (c Coord) String() string {
+  return fmt.Sprintf("(%d, %d)", c.X, c.Y)
+}
+
+func abs(n int) int {
+  if n < 0 { return -n }
+  return n
+}
+
+// Orthogonal directions in clockwise order, so turning is just moving through the list. Y grows downwards
+type Direction int
+
+const (
+  Up Direction = iota
+  Right
+  Down
+  Left
+)
+
+var Directions = [4]Direction{Up, Right, Down, Left}
+
+// Offsets to the 8 neighbours of a cell, clockwise starting from the one above
+var Deltas8 = [8]Coord{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+
+func (d Direction) Delta() Coord {
+  switch d {
+  case Up:
+    return Coord{0, -1}
+  case Right:
+    return Coord{1, 0}
+  case Down:
+    return Coord{0, 1}
+  default:
+    return Coord{-1, 0}
+  }
+}
+
+func (d Direction) TurnRight() Direction {
+  return (d + 1) % 4
+}
+
+func (d Direction) TurnLeft() Direction {
+  return (d + 3) % 4
+}
+
+func (d Direction) Opposite() Direction {
+  return (d + 2) % 4
+}
+
+func (d Direction) String() string {
+  return [4]string{"up", "right", "down", "left"}[d]
+}
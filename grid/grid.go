@@ -0,0 +1,116 @@
+// 2D maps shared by the days whose input is a grid of characters: coordinates, directions, neighbours and parsing
+package grid
+
+import (
+	"aoc2k24/errs"
+	"iter"
+)
+
+// Cells are stored row by row, so the cell at x, y is Cells[y * Width + x]
+type Grid[T any] struct {
+  Width int
+  Height int
+  Cells []T
+}
+
+func New[T any](width, height int) *Grid[T] {
+  return &Grid[T]{width, height, make([]T, width * height)}
+}
+
+// Parses a grid of characters. Every line has to be as wide as the first one
+func Parse(lines []string) (*Grid[rune], error) {
+  return ParseFunc(lines, func(r rune, _ Coord) (rune, error) { return r, nil })
+}
+
+// Parses a grid, turning every character into a cell with the given function, whose errors are returned as they are.
+// Empty lines at the end are left out
+func ParseFunc[T any](lines []string, cell func(r rune, c Coord) (T, error)) (*Grid[T], error) {
+  for len(lines) > 0 && lines[len(lines) - 1] == "" {
+    lines = lines[:len(lines) - 1]
+  }
+  if len(lines) == 0 { return nil, errs.Malformed("grid is empty") }
+  width := len([]rune(lines[0]))
+  g := New[T](width, len(lines))
+  for y, line := range lines {
+    row := []rune(line)
+    if len(row) != width { return nil, errs.Malformed("row %d is %d wide, expected %d", y + 1, len(row), width) }
+    for x, r := range row {
+      value, err := cell(r, Coord{x, y})
+      if err != nil { return nil, err }
+      g.Cells[y * width + x] = value
+    }
+  }
+  return g, nil
+}
+
+func (g *Grid[T]) Contains(c Coord) bool {
+  return c.X >= 0 && c.Y >= 0 && c.X < g.Width && c.Y < g.Height
+}
+
+func (g *Grid[T]) Index(c Coord) int {
+  return c.Y * g.Width + c.X
+}
+
+func (g *Grid[T]) CoordOf(i int) Coord {
+  return Coord{i % g.Width, i / g.Width}
+}
+
+func (g *Grid[T]) Get(c Coord) T {
+  return g.Cells[g.Index(c)]
+}
+
+// Like Get, but coordinates outside the grid give the zero value instead of panicking
+func (g *Grid[T]) Lookup(c Coord) (T, bool) {
+  if !g.Contains(c) {
+    var zero T
+    return zero, false
+  }
+  return g.Get(c), true
+}
+
+func (g *Grid[T]) Set(c Coord, value T) {
+  g.Cells[g.Index(c)] = value
+}
+
+func (g *Grid[T]) Clone() *Grid[T] {
+  return &Grid[T]{g.Width, g.Height, append([]T{}, g.Cells...)}
+}
+
+// Every cell with its coordinates, row by row
+func (g *Grid[T]) All() iter.Seq2[Coord, T] {
+  return func(yield func(Coord, T) bool) {
+    for i, value := range g.Cells {
+      if !yield(g.CoordOf(i), value) { return }
+    }
+  }
+}
+
+// The up to 4 orthogonal neighbours of c that are inside the grid, with the direction leading to each
+func (g *Grid[T]) Neighbours4(c Coord) iter.Seq2[Direction, Coord] {
+  return func(yield func(Direction, Coord) bool) {
+    for _, dir := range Directions {
+      next := c.Step(dir)
+      if !g.Contains(next) { continue }
+      if !yield(dir, next) { return }
+    }
+  }
+}
+
+// The up to 8 neighbours of c that are inside the grid, diagonals included
+func (g *Grid[T]) Neighbours8(c Coord) iter.Seq[Coord] {
+  return func(yield func(Coord) bool) {
+    for _, delta := range Deltas8 {
+      next := c.Add(delta)
+      if !g.Contains(next) { continue }
+      if !yield(next) { return }
+    }
+  }
+}
+
+// Coordinates of the first cell holding the value, row by row
+func Find[T comparable](g *Grid[T], value T) (Coord, bool) {
+  for i, cell := range g.Cells {
+    if cell == value { return g.CoordOf(i), true }
+  }
+  return Coord{}, false
+}
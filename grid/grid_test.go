@@ -0,0 +1,94 @@
+package grid_test
+
+import (
+	"aoc2k24/errs"
+	"aoc2k24/grid"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+  g, err := grid.Parse([]string{"#.", ".@", "", ""})
+  if err != nil { t.Fatal(err) }
+  if g.Width != 2 || g.Height != 2 { t.Errorf("got a %dx%d grid, expected 2x2", g.Width, g.Height) }
+  if g.Get(grid.Coord{X: 1, Y: 1}) != '@' { t.Errorf("got %c at (1, 1), expected @", g.Get(grid.Coord{X: 1, Y: 1})) }
+
+  for _, lines := range [][]string{{"###", "#.", "###"}, {"##", "###"}, {"##", "", "##"}, {}, {"", ""}} {
+    _, err := grid.Parse(lines)
+    if !errors.Is(err, errs.ErrMalformedInput) { t.Errorf("%q: got %v, expected a malformed input", lines, err) }
+  }
+}
+
+func TestParseFunc(t *testing.T) {
+  digit := func(r rune, c grid.Coord) (int, error) {
+    if r < '0' || r > '9' { return 0, errs.Malformed("%c at %s is not a digit", r, c) }
+    return int(r - '0'), nil
+  }
+  g, err := grid.ParseFunc([]string{"123", "456"}, digit)
+  if err != nil { t.Fatal(err) }
+  if !slices.Equal(g.Cells, []int{1, 2, 3, 4, 5, 6}) { t.Errorf("got cells %v", g.Cells) }
+
+  _, err = grid.ParseFunc([]string{"123", "4x6"}, digit)
+  if !errors.Is(err, errs.ErrMalformedInput) { t.Errorf("got %v, expected the error of the cell function", err) }
+  _, err = grid.ParseFunc([]string{"123", "4567"}, digit)
+  if !errors.Is(err, errs.ErrMalformedInput) { t.Errorf("got %v for a ragged row, expected a malformed input", err) }
+}
+
+func TestNeighbours(t *testing.T) {
+  g := grid.New[rune](3, 3)
+  tests := []struct {
+    c grid.Coord
+    expected4 []grid.Coord
+    expected8 int
+  }{
+    {grid.Coord{X: 0, Y: 0}, []grid.Coord{{X: 1, Y: 0}, {X: 0, Y: 1}}, 3},
+    {grid.Coord{X: 2, Y: 2}, []grid.Coord{{X: 2, Y: 1}, {X: 1, Y: 2}}, 3},
+    {grid.Coord{X: 1, Y: 0}, []grid.Coord{{X: 2, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}, 5},
+    {grid.Coord{X: 0, Y: 1}, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 2}}, 5},
+    {grid.Coord{X: 1, Y: 1}, []grid.Coord{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 1, Y: 2}, {X: 0, Y: 1}}, 8},
+  }
+  for _, test := range tests {
+    neighbours := []grid.Coord{}
+    for dir, next := range g.Neighbours4(test.c) {
+      if test.c.Step(dir) != next { t.Errorf("%s: %s is not %s of it", test.c, next, dir) }
+      neighbours = append(neighbours, next)
+    }
+    if !slices.Equal(neighbours, test.expected4) { t.Errorf("%s: got neighbours %v, expected %v", test.c, neighbours, test.expected4) }
+    count := 0
+    for next := range g.Neighbours8(test.c) {
+      if !g.Contains(next) || next.Manhattan(test.c) > 2 { t.Errorf("%s: got neighbour %s", test.c, next) }
+      count++
+    }
+    if count != test.expected8 { t.Errorf("%s: got %d neighbours with diagonals, expected %d", test.c, count, test.expected8) }
+  }
+}
+
+func TestLookupAndFind(t *testing.T) {
+  g, err := grid.Parse([]string{"..#", "#.."})
+  if err != nil { t.Fatal(err) }
+  for _, c := range []grid.Coord{{X: -1, Y: 0}, {X: 0, Y: -1}, {X: 3, Y: 0}, {X: 0, Y: 2}} {
+    value, isInside := g.Lookup(c)
+    if isInside || value != 0 { t.Errorf("%s: got %q (inside: %t), expected nothing", c, value, isInside) }
+  }
+  value, isInside := g.Lookup(grid.Coord{X: 2, Y: 0})
+  if !isInside || value != '#' { t.Errorf("got %q (inside: %t), expected #", value, isInside) }
+
+  c, isFound := grid.Find(g, '#')
+  if !isFound || c != (grid.Coord{X: 2, Y: 0}) { t.Errorf("got %s (found: %t), expected the first # at (2, 0)", c, isFound) }
+  _, isFound = grid.Find(g, '@')
+  if isFound { t.Error("found a value the grid doesn't hold") }
+}
+
+func TestDirections(t *testing.T) {
+  for _, dir := range grid.Directions {
+    if dir.TurnRight().TurnLeft() != dir { t.Errorf("%s: turning right then left gives %s", dir, dir.TurnRight().TurnLeft()) }
+    if dir.TurnRight().TurnRight() != dir.Opposite() { t.Errorf("%s: turning right twice doesn't give the opposite", dir) }
+    back := grid.Coord{X: 5, Y: 5}.Step(dir).Step(dir.Opposite())
+    if back != (grid.Coord{X: 5, Y: 5}) { t.Errorf("%s: stepping back ends at %s", dir, back) }
+  }
+  if grid.Up.TurnLeft() != grid.Left || grid.Left.TurnRight() != grid.Up { t.Error("turning doesn't wrap around") }
+  // Y grows downwards
+  if (grid.Coord{X: 1, Y: 1}).Step(grid.Up) != (grid.Coord{X: 1, Y: 0}) { t.Error("up doesn't decrease Y") }
+  if (grid.Coord{X: 1, Y: 1}).Step(grid.Right) != (grid.Coord{X: 2, Y: 1}) { t.Error("right doesn't increase X") }
+}
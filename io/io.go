@@ -2,22 +2,64 @@ package io
 
 import (
 	"aoc2k24/constants"
+	"aoc2k24/grid"
 	"bufio"
-	"fmt"
+	"io"
 	"os"
 )
 
+// Stands for stdin wherever an input path is expected
+const Stdin = "-"
+
+// Longest line LinesFrom accepts. Some inputs are a single line of tens of thousands of characters, longer than the
+// default of bufio.Scanner
+const maxLineLength = 16 * 1024 * 1024
+
 func GetLinesFor(day constants.DayIndex, ver constants.VersionIndex) ([]string, error) {
-  file, err := os.Open(fmt.Sprintf("/home/pablo/projects/aoc/2024/go/files/%d-%d.txt", day, ver))
+  path, err := resolvePath(day, ver)
+  if err != nil {
+    return nil, err
+  }
+  return GetLinesFrom(path)
+}
+
+// Lines of the input, read from its path if it has one (e.g. given with -input), or else from its day's input file
+func GetLines(input InputInfo) ([]string, error) {
+  if input.Path != "" { return GetLinesFrom(input.Path) }
+  return GetLinesFor(input.Day, input.Ver)
+}
+
+// Lines of the file at path, or of stdin if path is Stdin
+func GetLinesFrom(path string) ([]string, error) {
+  if path == Stdin { return LinesFrom(os.Stdin) }
+  file, err := os.Open(path)
+  if os.IsNotExist(err) { return nil, &InputNotFoundError{path, []string{path}} }
   if err != nil {
     return nil, err
   }
   defer file.Close()
+  return LinesFrom(file)
+}
 
+// Every line read from r, without line endings
+func LinesFrom(r io.Reader) ([]string, error) {
   var lines []string
-  scanner := bufio.NewScanner(file)
+  scanner := bufio.NewScanner(r)
+  scanner.Buffer(nil, maxLineLength)
   for scanner.Scan() {
     lines = append(lines, scanner.Text())
   }
   return lines, scanner.Err()
 }
+
+// Everything read from r, for inputs that aren't made of lines
+func Bytes(r io.Reader) ([]byte, error) {
+  return io.ReadAll(r)
+}
+
+// A grid of characters read from r, one row per line
+func Grid(r io.Reader) (*grid.Grid[rune], error) {
+  lines, err := LinesFrom(r)
+  if err != nil { return nil, err }
+  return grid.Parse(lines)
+}
@@ -0,0 +1,93 @@
+package io_test
+
+import (
+	"aoc2k24/errs"
+	"aoc2k24/grid"
+	"aoc2k24/io"
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLinesFrom(t *testing.T) {
+  tests := []struct {
+    name string
+    content string
+    expected []string
+  }{
+    {"LF", "a\nb\n", []string{"a", "b"}},
+    {"CRLF", "a\r\nb\r\n", []string{"a", "b"}},
+    {"no trailing newline", "a\nb", []string{"a", "b"}},
+    {"blank lines", "a\n\nb\n\n", []string{"a", "", "b", ""}},
+    {"empty", "", nil},
+  }
+  for _, test := range tests {
+    lines, err := io.LinesFrom(strings.NewReader(test.content))
+    if err != nil { t.Errorf("%s: %v", test.name, err) }
+    if !slices.Equal(lines, test.expected) { t.Errorf("%s: got %q, expected %q", test.name, lines, test.expected) }
+  }
+}
+
+// Lines far longer than the 64KiB bufio.Scanner allows by default are read whole, up to 16MiB
+func TestLinesFromLongLines(t *testing.T) {
+  long := strings.Repeat("x", 1 << 20)
+  lines, err := io.LinesFrom(strings.NewReader(long + "\nshort\n"))
+  if err != nil { t.Fatal(err) }
+  if len(lines) != 2 || lines[0] != long || lines[1] != "short" { t.Errorf("got %d lines, expected the long line and the short one", len(lines)) }
+
+  _, err = io.LinesFrom(strings.NewReader(strings.Repeat("x", 16 << 20 + 1)))
+  if !errors.Is(err, bufio.ErrTooLong) { t.Errorf("got %v for a line over 16MiB, expected it to be too long", err) }
+}
+
+// Unlike LinesFrom, line endings are kept
+func TestBytes(t *testing.T) {
+  content, err := io.Bytes(strings.NewReader("a\r\nb"))
+  if err != nil { t.Fatal(err) }
+  if string(content) != "a\r\nb" { t.Errorf("got %q", content) }
+}
+
+func TestGrid(t *testing.T) {
+  g, err := io.Grid(strings.NewReader("#.@\r\n...\n\n"))
+  if err != nil { t.Fatal(err) }
+  if g.Width != 3 || g.Height != 2 || g.Get(grid.Coord{X: 2, Y: 0}) != '@' { t.Errorf("got a %dx%d grid with %q", g.Width, g.Height, string(g.Cells)) }
+
+  _, err = io.Grid(strings.NewReader("#.@\n..\n"))
+  if !errors.Is(err, errs.ErrMalformedInput) { t.Errorf("got %v for a ragged grid, expected a malformed input", err) }
+}
+
+func TestGetLinesFrom(t *testing.T) {
+  lines, err := io.GetLinesFrom(writeFile(t, "1 2\r\n3 4"))
+  if err != nil { t.Fatal(err) }
+  if !slices.Equal(lines, []string{"1 2", "3 4"}) { t.Errorf("got %q from the file", lines) }
+
+  missing := filepath.Join(t.TempDir(), "missing.txt")
+  _, err = io.GetLinesFrom(missing)
+  var notFound *io.InputNotFoundError
+  if !errors.As(err, &notFound) || !errors.Is(err, errs.ErrInputNotFound) || notFound.Name != missing {
+    t.Errorf("got %v for a missing file, expected it not to be found", err)
+  }
+}
+
+func TestGetLinesFromStdin(t *testing.T) {
+  file, err := os.Open(writeFile(t, "from\nstdin\n"))
+  if err != nil { t.Fatal(err) }
+  defer file.Close()
+  stdin := os.Stdin
+  os.Stdin = file
+  defer func() { os.Stdin = stdin }()
+
+  lines, err := io.GetLines(io.InputInfo{Day: 1, Path: io.Stdin})
+  if err != nil { t.Fatal(err) }
+  if !slices.Equal(lines, []string{"from", "stdin"}) { t.Errorf("got %q from stdin", lines) }
+}
+
+func writeFile(t *testing.T, content string) string {
+  path := filepath.Join(t.TempDir(), "input.txt")
+  err := os.WriteFile(path, []byte(content), 0644)
+  if err != nil { t.Fatal(err) }
+  return path
+}
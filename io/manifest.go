@@ -0,0 +1,113 @@
+package io
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Lives in the inputs directory and describes every input file in it, keyed by day
+const ManifestFile = "manifest.json"
+
+// What is known about one input file. Expected answers left empty aren't checked
+type InputInfo struct {
+  Day constants.DayIndex `json:"-"`
+  Ver constants.VersionIndex `json:"version"`
+  Name string `json:"name"`
+  Description string `json:"description,omitempty"`
+  Part1 string `json:"part1,omitempty"`
+  Part2 string `json:"part2,omitempty"`
+  Params map[string]int `json:"params,omitempty"`
+  // Where to read the input from instead of its day's input file, if set. Stdin reads it from stdin
+  Path string `json:"-"`
+}
+
+// Expected answers by part, only for the parts that have one
+func (i InputInfo) Expected() map[constants.PartIndex]string {
+  expected := make(map[constants.PartIndex]string)
+  if i.Part1 != "" { expected[constants.Part1] = i.Part1 }
+  if i.Part2 != "" { expected[constants.Part2] = i.Part2 }
+  return expected
+}
+
+type Manifest struct {
+  inputs map[constants.DayIndex][]InputInfo
+}
+
+// Reads the manifest from the inputs directory. A missing manifest isn't an error, just an empty one
+func LoadManifest() (*Manifest, error) {
+  manifest := &Manifest{make(map[constants.DayIndex][]InputInfo)}
+  path, err := ResolveFile(ManifestFile)
+  if errors.Is(err, errs.ErrInputNotFound) { return manifest, nil }
+  if err != nil { return nil, err }
+  data, err := os.ReadFile(path)
+  if err != nil { return nil, err }
+
+  byDay := make(map[string][]InputInfo)
+  err = json.Unmarshal(data, &byDay)
+  if err != nil { return nil, errs.Malformed("%s: %v", ManifestFile, err) }
+  for key, inputs := range byDay {
+    day, err := strconv.Atoi(key)
+    if err != nil { return nil, errs.Malformed("%s: invalid day %q", ManifestFile, key) }
+    err = validateInputs(inputs)
+    if err != nil { return nil, errs.Malformed("%s, day %d: %v", ManifestFile, day, err) }
+    for i := range inputs {
+      inputs[i].Day = constants.DayIndex(day)
+    }
+    sort.Slice(inputs, func(a, b int) bool { return inputs[a].Ver < inputs[b].Ver })
+    manifest.inputs[constants.DayIndex(day)] = inputs
+  }
+  return manifest, nil
+}
+
+// Versions and names have to be unique within a day, otherwise -v would be ambiguous
+func validateInputs(inputs []InputInfo) error {
+  versions := make(map[constants.VersionIndex]bool)
+  names := make(map[string]bool)
+  for _, input := range inputs {
+    if input.Name == "" { return fmt.Errorf("version %d has no name", input.Ver) }
+    if versions[input.Ver] { return fmt.Errorf("version %d listed twice", input.Ver) }
+    if names[input.Name] { return fmt.Errorf("name %q listed twice", input.Name) }
+    versions[input.Ver] = true
+    names[input.Name] = true
+  }
+  return nil
+}
+
+// Every input of the day, sorted by version
+func (m *Manifest) Inputs(day constants.DayIndex) []InputInfo {
+  return m.inputs[day]
+}
+
+// Every input of every day, sorted by day and then version
+func (m *Manifest) All() []InputInfo {
+  days := make([]constants.DayIndex, 0, len(m.inputs))
+  for day := range m.inputs {
+    days = append(days, day)
+  }
+  sort.Slice(days, func(a, b int) bool { return days[a] < days[b] })
+  all := []InputInfo{}
+  for _, day := range days {
+    all = append(all, m.inputs[day]...)
+  }
+  return all
+}
+
+func (m *Manifest) Lookup(day constants.DayIndex, ver constants.VersionIndex) (InputInfo, bool) {
+  for _, input := range m.inputs[day] {
+    if input.Ver == ver { return input, true }
+  }
+  return InputInfo{Day: day, Ver: ver}, false
+}
+
+func (m *Manifest) Find(day constants.DayIndex, name string) (InputInfo, bool) {
+  for _, input := range m.inputs[day] {
+    if input.Name == name { return input, true }
+  }
+  return InputInfo{}, false
+}
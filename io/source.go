@@ -0,0 +1,127 @@
+package io
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variable that can point to the directory holding the puzzle inputs
+const InputsEnvVar = "AOC2K24_INPUTS"
+
+// Name of the inputs directory when looked up relative to the executable or the module
+const inputsDirName = "files"
+
+var inputRoot = ""
+
+// Sets the inputs directory given explicitly (e.g. via the -inputs flag). Takes precedence over every other location
+func SetInputRoot(root string) {
+  inputRoot = root
+}
+
+// Usage of the -inputs flag of every command, which only differ in what the directory is for, e.g. "Directory to
+// download to"
+func InputsFlagUsage(what string) string {
+  return fmt.Sprintf("%s. Defaults to $%s, then the files directory next to the executable or in the module root", what, InputsEnvVar)
+}
+
+type InputNotFoundError struct {
+  Name string
+  Tried []string
+}
+
+func (e *InputNotFoundError) Error() string {
+  return fmt.Sprintf("input %s not found. Paths tried:\n  %s", e.Name, strings.Join(e.Tried, "\n  "))
+}
+
+func (e *InputNotFoundError) Unwrap() error {
+  return errs.ErrInputNotFound
+}
+
+func fileName(day constants.DayIndex, ver constants.VersionIndex) string {
+  return fmt.Sprintf("%d-%d.txt", day, ver)
+}
+
+func resolvePath(day constants.DayIndex, ver constants.VersionIndex) (string, error) {
+  return ResolveFile(fileName(day, ver))
+}
+
+// Resolves the path of a file in the inputs directory, checking every candidate root in order of precedence:
+// -inputs flag, environment variable, directory of the executable and finally the module root
+func ResolveFile(name string) (string, error) {
+  tried := []string{}
+  for _, root := range candidateRoots() {
+    path := filepath.Join(root, name)
+    tried = append(tried, path)
+    info, err := os.Stat(path)
+    if err == nil && !info.IsDir() {
+      return path, nil
+    }
+  }
+  return "", &InputNotFoundError{name, tried}
+}
+
+// Directory downloaded inputs are written to: the first candidate root that exists, in the same order of precedence as
+// ResolveFile
+func InputsDir() (string, error) {
+  roots := candidateRoots()
+  for _, root := range roots {
+    info, err := os.Stat(root)
+    if err == nil && info.IsDir() { return root, nil }
+  }
+  return "", fmt.Errorf("%w: no inputs directory found. Paths tried:\n  %s", errs.ErrInputNotFound, strings.Join(roots, "\n  "))
+}
+
+// Path a version of the day's input is written to when saved in the given directory
+func FilePath(dir string, day constants.DayIndex, ver constants.VersionIndex) string {
+  return filepath.Join(dir, fileName(day, ver))
+}
+
+func candidateRoots() []string {
+  roots := []string{}
+  if inputRoot != "" {
+    roots = append(roots, inputRoot)
+  }
+  envRoot, hasEnvRoot := os.LookupEnv(InputsEnvVar)
+  if hasEnvRoot && envRoot != "" {
+    roots = append(roots, envRoot)
+  }
+  exe, err := os.Executable()
+  if err == nil {
+    roots = append(roots, filepath.Join(filepath.Dir(exe), inputsDirName))
+  }
+  moduleRoot := ModuleRoot()
+  if moduleRoot != "" {
+    roots = append(roots, filepath.Join(moduleRoot, inputsDirName))
+  }
+  return dedupe(roots)
+}
+
+// Walks up from the working directory until a go.mod file is found. Empty if there is none, e.g. when the executable
+// runs outside the module
+func ModuleRoot() string {
+  dir, err := os.Getwd()
+  if err != nil { return "" }
+  for {
+    _, err := os.Stat(filepath.Join(dir, "go.mod"))
+    if err == nil { return dir }
+    parent := filepath.Dir(dir)
+    if parent == dir { return "" }
+    dir = parent
+  }
+}
+
+func dedupe(paths []string) []string {
+  seen := make(map[string]struct{})
+  unique := []string{}
+  for _, path := range paths {
+    clean := filepath.Clean(path)
+    _, isSeen := seen[clean]; if isSeen { continue }
+    seen[clean] = struct{}{}
+    unique = append(unique, clean)
+  }
+  return unique
+}
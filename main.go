@@ -1,14 +1,166 @@
 package main
 
 import (
+	"aoc2k24/bench"
 	"aoc2k24/constants"
+	_ "aoc2k24/days"
+	"aoc2k24/errs"
+	"aoc2k24/io"
+	"aoc2k24/report"
 	"aoc2k24/selector"
+	"aoc2k24/submit"
+	"aoc2k24/trace"
 	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
 )
  
+// Subcommands, run as aoc2k24 <command> [flags]. Each one returns the exit code. Without a subcommand the selected days
+// are solved
+var commands = map[string]func(args []string) int{
+  "fetch": runFetch,
+  "submit": runSubmit,
+  "new": runNew,
+  "disasm": runDisasm,
+  "debug": runDebug,
+}
+
 func main() {
-  dayParam := flag.Int("day", 10, "The Advent of Code 2024 day you wish to see")
-  versionParam := flag.Int("v", 0, "The version. 0 is full puzzle input, successive ones are test data")
+  if len(os.Args) > 1 {
+    command, isCommand := commands[os.Args[1]]
+    if isCommand { os.Exit(command(os.Args[2:])) }
+  }
+  dayParam := flag.String("day", "all", "The Advent of Code 2024 days you wish to see: all, a single day (5), a range (1-10) or a comma separated list (1,3,5-7)")
+  partParam := flag.String("part", "both", "The part to solve: 1, 2 or both")
+  versionParam := flag.String("v", "0", fmt.Sprintf("The version, by number or by its name in %s. 0 (full) is the puzzle input, successive ones are test data", io.ManifestFile))
+  inputsParam := flag.String("inputs", "", io.InputsFlagUsage("Directory holding the puzzle inputs"))
+  formatParam := flag.String("format", "text", "How results are printed: text, or json and csv for scripts. Errors always go to stderr")
+  inputParam := flag.String("input", "", "Read the input of the selected day from this file, or from stdin if -, instead of its input file. -v then only picks the parameters")
+  jobsParam := flag.Int("j", runtime.NumCPU(), "How many days are solved at the same time. Their times then overlap, each slowed down by the others: -j 1 runs them one after the other, for undisturbed timings")
+  listParam := flag.Bool("list", false, "List the registered days, the parts each one solves and the names of its inputs")
+  benchParam := flag.Bool("bench", false, "Benchmark every part of the selected days instead of just solving them")
+  benchJsonParam := flag.String("bench-json", "", "With -bench, also write the results as JSON to this file (- for stdout)")
+  traceOutParam := flag.String("trace-out", "", "Write traces to this file instead of stderr")
+  flag.Func("trace", "Enables the trace categories of a day: d16 (its default ones), d16:frontier,dijkstra or d16:all. Can be repeated; -list shows the categories of each day", trace.Enable)
+  var paramFlags selector.ParamFlags
+  flag.Var(&paramFlags, "param", fmt.Sprintf("Overrides a puzzle parameter as [day.]name=value, on top of those in %s. Can be repeated; -list shows the parameters of each day", io.ManifestFile))
   flag.Parse()
-  selector.RunDay(constants.DayIndex(*dayParam), constants.VersionIndex(*versionParam))
+  if *traceOutParam != "" {
+    traceFile, err := os.Create(*traceOutParam)
+    if err != nil {
+      fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+      os.Exit(errs.ExitUsage)
+    }
+    // Left open until the process exits, since main always ends in os.Exit
+    trace.SetOutput(traceFile)
+  }
+  io.SetInputRoot(*inputsParam)
+  manifest, err := io.LoadManifest()
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    os.Exit(errs.ExitCode(err))
+  }
+  if *listParam {
+    report.Days(os.Stdout, manifest)
+    return
+  }
+  days, err := selector.ParseDays(*dayParam)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    os.Exit(errs.ExitUsage)
+  }
+  parts, err := selector.ParseParts(*partParam)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    os.Exit(errs.ExitUsage)
+  }
+  format, err := report.ParseFormat(*formatParam)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    os.Exit(errs.ExitUsage)
+  }
+  ledger, err := submit.LoadLedger()
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    os.Exit(errs.ExitCode(err))
+  }
+  inputs, err := selector.ResolveVersions(manifest, days, *versionParam)
+  if err == nil && *inputParam != "" {
+    inputs, err = selector.UsePath(inputs, *inputParam)
+  }
+  if err == nil {
+    // Answers accepted by the site are checked too, before overrides clear the expected answers of what they change
+    inputs, err = selector.ApplyParams(ledger.FillExpected(inputs), paramFlags)
+  }
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    os.Exit(errs.ExitUsage)
+  }
+  if *benchParam {
+    os.Exit(runBench(inputs, parts, *benchJsonParam))
+  }
+  start := time.Now()
+  runs := selector.RunDays(inputs, parts, *jobsParam)
+  wallTime := time.Since(start)
+  // Every error is reported, but the exit code is the one of the first failing day
+  exitCode := errs.ExitOK
+  err = printRuns(runs, wallTime, format)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    exitCode = errs.ExitUnknown
+  }
+  for _, run := range runs {
+    if run.Err == nil { continue }
+    fmt.Fprintf(os.Stderr, "Error: %v\n", run.Err)
+    if exitCode == errs.ExitOK { exitCode = errs.ExitCode(run.Err) }
+  }
+  os.Exit(exitCode)
+}
+
+func printRuns(runs []selector.DayRun, wallTime time.Duration, format report.Format) error {
+  switch {
+  case format == report.FormatJSON:
+    return report.JSON(os.Stdout, runs)
+  case format == report.FormatCSV:
+    return report.CSV(os.Stdout, runs)
+  case len(runs) == 1:
+    report.Text(os.Stdout, runs[0].Results)
+  default:
+    report.Table(os.Stdout, runs, wallTime)
+  }
+  return nil
+}
+
+func runBench(inputs []io.InputInfo, parts []constants.PartIndex, jsonPath string) int {
+  stats, failures := bench.Run(inputs, parts)
+
+  if jsonPath != "-" {
+    report.Bench(os.Stdout, stats)
+  }
+  if jsonPath != "" {
+    err := writeBenchJSON(stats, jsonPath)
+    if err != nil {
+      fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+      return errs.ExitUnknown
+    }
+  }
+  exitCode := errs.ExitOK
+  for _, err := range failures {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    if exitCode == errs.ExitOK { exitCode = errs.ExitCode(err) }
+  }
+  return exitCode
 }
+
+func writeBenchJSON(stats []bench.Stats, path string) error {
+  if path == "-" {
+    return report.BenchJSON(os.Stdout, stats)
+  }
+  file, err := os.Create(path)
+  if err != nil { return err }
+  defer file.Close()
+  return report.BenchJSON(file, stats)
+}
+
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/io"
+	"aoc2k24/scaffold"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Generates the skeleton of a new day. It writes Go sources, so it has to run from within the module
+func runNew(args []string) int {
+  flags := flag.NewFlagSet("new", flag.ExitOnError)
+  dayParam := flags.String("day", "", "The day to generate, between 1 and 25")
+  inputsParam := flags.String("inputs", "", io.InputsFlagUsage("Directory to create the empty inputs in"))
+  flags.Parse(args)
+
+  day, err := strconv.Atoi(*dayParam)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: invalid day %q, expected a number between 1 and 25\n", *dayParam)
+    return errs.ExitUsage
+  }
+  root := io.ModuleRoot()
+  if root == "" {
+    fmt.Fprintln(os.Stderr, "Error: no go.mod found, new has to run from within the module")
+    return errs.ExitUsage
+  }
+  io.SetInputRoot(*inputsParam)
+  inputsDir, err := io.InputsDir()
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitCode(err)
+  }
+  written, err := scaffold.Generate(root, inputsDir, constants.DayIndex(day))
+  for _, path := range written {
+    fmt.Printf("Wrote %s\n", path)
+  }
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitUnknown
+  }
+  return errs.ExitOK
+}
@@ -0,0 +1,16 @@
+package registry
+
+import "aoc2k24/constants"
+
+// Optional interface for solvers that can tell more about an answer than the answer itself, e.g. which of d13's
+// machines can be won. Extras are only shown by the machine readable output formats, so they must encode as JSON
+type Explainer interface {
+  Extras(part constants.PartIndex) any
+}
+
+// Extras of a part the solver already solved, or nil if it has none
+func Extras(s Solver, part constants.PartIndex) any {
+  explainer, isExplainer := s.(Explainer)
+  if !isExplainer { return nil }
+  return explainer.Extras(part)
+}
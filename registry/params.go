@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"aoc2k24/errs"
+	"fmt"
+	"slices"
+)
+
+// A puzzle parameter that changes between inputs, like the grid size of an example. Default is the value of the full
+// puzzle input
+type Param struct {
+  Name string
+  Default int
+  // Lowest value the solver accepts. Left at 0 by parameters that are counts, since none of them can be negative
+  Min int
+  Usage string
+}
+
+// Values below the minimum would make the solver panic or give nonsense, so they're refused before it runs
+func (p Param) Check(value int) error {
+  if value < p.Min { return fmt.Errorf("parameter %s must be at least %d, got %d", p.Name, p.Min, value) }
+  return nil
+}
+
+// Optional interface for solvers with parameters. SetParam is called once per declared parameter before Parse
+type Configurable interface {
+  Params() []Param
+  SetParam(name string, value int)
+}
+
+// Parameters declared by the day's solver, if any
+func (e Entry) Params() []Param {
+  configurable, isConfigurable := e.New().(Configurable)
+  if !isConfigurable { return nil }
+  return configurable.Params()
+}
+
+// The declared parameter with the given name, if any
+func (e Entry) Param(name string) (Param, bool) {
+  params := e.Params()
+  i := slices.IndexFunc(params, func(p Param) bool { return p.Name == name })
+  if i < 0 { return Param{}, false }
+  return params[i], true
+}
+
+// Sets every parameter of the solver, to the given value or else to its default. Values for parameters the solver
+// doesn't declare are an error, since they'd be silently ignored otherwise, and so are values below their minimum
+func Configure(s Solver, values map[string]int) error {
+  params := []Param{}
+  configurable, isConfigurable := s.(Configurable)
+  if isConfigurable { params = configurable.Params() }
+  for name := range values {
+    isDeclared := slices.ContainsFunc(params, func(p Param) bool { return p.Name == name })
+    if !isDeclared { return errs.Malformed("unknown parameter %q", name) }
+  }
+  for _, param := range params {
+    value, isSet := values[param.Name]
+    if !isSet { value = param.Default }
+    err := param.Check(value)
+    if err != nil { return errs.Malformed("%v", err) }
+    configurable.SetParam(param.Name, value)
+  }
+  return nil
+}
@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"aoc2k24/constants"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Every day implements a Solver. Parse receives the puzzle input and each part works over whatever was parsed
+// Parse should fail with errs.ErrMalformedInput and parts with errs.ErrPartUnsolvable when they can't produce an answer
+type Solver interface {
+  Parse(lines []string) error
+  Part1() (Answer, error)
+  Part2() (Answer, error)
+}
+
+// Runs the given part of an already parsed Solver
+func Solve(s Solver, part constants.PartIndex) (Answer, error) {
+  if part == constants.Part1 {
+    return s.Part1()
+  }
+  return s.Part2()
+}
+
+// Builds a fresh Solver, so that every run starts from a clean state
+type Factory func() Solver
+
+// Most answers are numbers, which go in Value. Those that aren't (e.g. coordinates or comma separated lists) go in Text
+type Answer struct {
+  Value int64
+  Text string
+}
+
+func Int(n int) Answer {
+  return Answer{Value: int64(n)}
+}
+
+func Text(s string) Answer {
+  return Answer{Text: s}
+}
+
+func (a Answer) IsText() bool {
+  return a.Text != ""
+}
+
+func (a Answer) String() string {
+  if a.IsText() { return a.Text }
+  return strconv.FormatInt(a.Value, 10)
+}
+
+type Entry struct {
+  Day constants.DayIndex
+  New Factory
+  Parts []constants.PartIndex
+}
+
+func (e Entry) HasPart(part constants.PartIndex) bool {
+  return slices.Contains(e.Parts, part)
+}
+
+var entries = make(map[constants.DayIndex]Entry)
+
+// Called from the init function of each day package. If no parts are given, the day is assumed to solve both
+func Register(day constants.DayIndex, new Factory, parts ...constants.PartIndex) {
+  _, exists := entries[day]; if exists {
+    panic(fmt.Sprintf("Day %d is registered more than once", day))
+  }
+  if len(parts) == 0 {
+    parts = []constants.PartIndex{constants.Part1, constants.Part2}
+  }
+  entries[day] = Entry{day, new, parts}
+}
+
+func Get(day constants.DayIndex) (Entry, bool) {
+  entry, exists := entries[day]
+  return entry, exists
+}
+
+// Returns the registered days in ascending order
+func Days() []constants.DayIndex {
+  days := make([]constants.DayIndex, 0, len(entries))
+  for day := range entries {
+    days = append(days, day)
+  }
+  slices.Sort(days)
+  return days
+}
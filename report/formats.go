@@ -0,0 +1,103 @@
+package report
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/selector"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// How results are printed. Text is meant for people, the rest for scripts and dashboards
+type Format string
+
+const (
+  FormatText Format = "text"
+  FormatJSON Format = "json"
+  FormatCSV Format = "csv"
+)
+
+func ParseFormat(spec string) (Format, error) {
+  format := Format(spec)
+  if format != FormatText && format != FormatJSON && format != FormatCSV {
+    return "", fmt.Errorf("invalid format %q, expected text, json or csv", spec)
+  }
+  return format, nil
+}
+
+// One part of one input in the machine readable formats: its answer, its error, or both when the answer is wrong.
+// Errors that aren't tied to a part (e.g. a missing input) get a record of their own with part 0
+type Record struct {
+  Day constants.DayIndex `json:"day"`
+  Version constants.VersionIndex `json:"version"`
+  Part constants.PartIndex `json:"part"`
+  Answer string `json:"answer,omitempty"`
+  DurationNs int64 `json:"durationNs"`
+  Error string `json:"error,omitempty"`
+  Extras any `json:"extras,omitempty"`
+}
+
+func Records(runs []selector.DayRun) []Record {
+  records := []Record{}
+  for _, run := range runs {
+    first := len(records)
+    for _, result := range run.Results {
+      records = append(records, Record{run.Day, run.Ver, result.Part, result.Answer.String(), result.Duration.Nanoseconds(), "", result.Extras})
+    }
+    for _, err := range splitErrors(run.Err) {
+      part := constants.PartIndex(0)
+      var dayErr *errs.DayError
+      if errors.As(err, &dayErr) { part = dayErr.Part }
+      isResult := false
+      for i := first; i < len(records); i++ {
+        if part == 0 || records[i].Part != part { continue }
+        records[i].Error = err.Error()
+        isResult = true
+      }
+      if !isResult { records = append(records, Record{Day: run.Day, Version: run.Ver, Part: part, Error: err.Error()}) }
+    }
+  }
+  return records
+}
+
+// The errors joined by errors.Join, each on its own
+func splitErrors(err error) []error {
+  if err == nil { return nil }
+  joined, isJoined := err.(interface{ Unwrap() []error })
+  if !isJoined { return []error{err} }
+  split := []error{}
+  for _, inner := range joined.Unwrap() {
+    split = append(split, splitErrors(inner)...)
+  }
+  return split
+}
+
+func JSON(w io.Writer, runs []selector.DayRun) error {
+  encoder := json.NewEncoder(w)
+  encoder.SetIndent("", "  ")
+  return encoder.Encode(Records(runs))
+}
+
+// Same columns as the JSON fields. Extras don't fit in a column, so they're written as JSON
+func CSV(w io.Writer, runs []selector.DayRun) error {
+  writer := csv.NewWriter(w)
+  writer.Write([]string{"day", "version", "part", "answer", "durationNs", "error", "extras"})
+  for _, record := range Records(runs) {
+    extras := ""
+    if record.Extras != nil {
+      encoded, err := json.Marshal(record.Extras)
+      if err != nil { return err }
+      extras = string(encoded)
+    }
+    writer.Write([]string{
+      strconv.Itoa(int(record.Day)), strconv.Itoa(int(record.Version)), strconv.Itoa(int(record.Part)), record.Answer,
+      strconv.FormatInt(record.DurationNs, 10), record.Error, extras,
+    })
+  }
+  writer.Flush()
+  return writer.Error()
+}
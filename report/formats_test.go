@@ -0,0 +1,105 @@
+package report_test
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"aoc2k24/report"
+	"aoc2k24/selector"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func result(day constants.DayIndex, part constants.PartIndex, answer registry.Answer, extras any) selector.Result {
+  return selector.Result{Day: day, Part: part, Answer: answer, Duration: time.Duration(part) * time.Millisecond, Extras: extras}
+}
+
+var (
+  parseErr = &errs.DayError{Day: 2, Ver: 1, Err: errs.Malformed("line 3 is empty")}
+  wrongErr = &errs.DayError{Day: 3, Part: constants.Part2, Err: errs.WrongAnswer("6", "7")}
+)
+
+var formatTests = []struct {
+  name string
+  run selector.DayRun
+  expected []report.Record
+}{
+  {
+    "success",
+    selector.DayRun{Day: 1, Results: []selector.Result{result(1, 1, registry.Int(11), nil), result(1, 2, registry.Int(31), nil)}},
+    []report.Record{{Day: 1, Part: 1, Answer: "11", DurationNs: 1e6}, {Day: 1, Part: 2, Answer: "31", DurationNs: 2e6}},
+  },
+  {
+    // Parsing fails every part, so neither has a result and the error gets a record of its own
+    "parse error",
+    selector.DayRun{Day: 2, Ver: 1, Err: parseErr},
+    []report.Record{{Day: 2, Version: 1, Part: 0, Error: parseErr.Error()}},
+  },
+  {
+    // The wrong answer is kept along with its error, and the right one has none
+    "wrong answer",
+    selector.DayRun{Day: 3, Results: []selector.Result{result(3, 1, registry.Int(5), nil), result(3, 2, registry.Int(6), nil)}, Err: errors.Join(wrongErr)},
+    []report.Record{{Day: 3, Part: 1, Answer: "5", DurationNs: 1e6}, {Day: 3, Part: 2, Answer: "6", DurationNs: 2e6, Error: wrongErr.Error()}},
+  },
+  {
+    "extras",
+    selector.DayRun{Day: 4, Results: []selector.Result{result(4, 1, registry.Text(`1,2 "x"`), map[string]int{"steps": 3})}},
+    []report.Record{{Day: 4, Part: 1, Answer: `1,2 "x"`, DurationNs: 1e6, Extras: map[string]int{"steps": 3}}},
+  },
+}
+
+func TestRecords(t *testing.T) {
+  for _, test := range formatTests {
+    records := report.Records([]selector.DayRun{test.run})
+    if !reflect.DeepEqual(records, test.expected) { t.Errorf("%s: got %+v, expected %+v", test.name, records, test.expected) }
+  }
+}
+
+func TestJSON(t *testing.T) {
+  for _, test := range formatTests {
+    var written bytes.Buffer
+    err := report.JSON(&written, []selector.DayRun{test.run})
+    if err != nil { t.Fatalf("%s: %v", test.name, err) }
+    expected, _ := json.MarshalIndent(test.expected, "", "  ")
+    if written.String() != string(expected) + "\n" { t.Errorf("%s: got\n%s\nexpected\n%s", test.name, written.String(), expected) }
+  }
+
+  // Only the fields that apply are written
+  var written bytes.Buffer
+  report.JSON(&written, []selector.DayRun{formatTests[1].run})
+  var decoded []map[string]any
+  err := json.Unmarshal(written.Bytes(), &decoded)
+  if err != nil { t.Fatal(err) }
+  if _, hasAnswer := decoded[0]["answer"]; hasAnswer { t.Errorf("parse error record has an answer: %v", decoded[0]) }
+  if _, hasExtras := decoded[0]["extras"]; hasExtras { t.Errorf("parse error record has extras: %v", decoded[0]) }
+}
+
+func TestCSV(t *testing.T) {
+  runs := []selector.DayRun{}
+  for _, test := range formatTests {
+    runs = append(runs, test.run)
+  }
+  var written bytes.Buffer
+  err := report.CSV(&written, runs)
+  if err != nil { t.Fatal(err) }
+  // Commas and quotes in answers and extras are quoted, so the columns stay aligned
+  if !strings.Contains(written.String(), `,"1,2 ""x""",1000000,,"{""steps"":3}"`) { t.Errorf("answer and extras not quoted:\n%s", written.String()) }
+  rows, err := csv.NewReader(&written).ReadAll()
+  if err != nil { t.Fatalf("written CSV doesn't read back: %v", err) }
+  expected := [][]string{
+    {"day", "version", "part", "answer", "durationNs", "error", "extras"},
+    {"1", "0", "1", "11", "1000000", "", ""},
+    {"1", "0", "2", "31", "2000000", "", ""},
+    {"2", "1", "0", "", "0", parseErr.Error(), ""},
+    {"3", "0", "1", "5", "1000000", "", ""},
+    {"3", "0", "2", "6", "2000000", wrongErr.Error(), ""},
+    {"4", "0", "1", `1,2 "x"`, "1000000", "", `{"steps":3}`},
+  }
+  if !reflect.DeepEqual(rows, expected) { t.Errorf("got rows\n%q\nexpected\n%q", rows, expected) }
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+	"aoc2k24/bench"
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	aocio "aoc2k24/io"
+	"aoc2k24/registry"
+	"aoc2k24/selector"
+	"aoc2k24/trace"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Single place where results get printed, so that days only have to worry about computing their answers
+func Text(w io.Writer, results []selector.Result) {
+  for _, result := range results {
+    fmt.Fprintf(w, "Day %d, part %d: %s (%s)\n", result.Day, result.Part, result.Answer, result.Duration.Round(time.Microsecond))
+  }
+}
+
+// Aligned summary of several days, one row per day and the wall time of the whole run at the bottom. Days solved at
+// the same time slow each other down, so their own times overlap and only add up to the wall time with -j 1
+func Table(w io.Writer, runs []selector.DayRun, wallTime time.Duration) {
+  tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+  fmt.Fprint(tw, "Day\tPart 1\tPart 2\tTime\t\n")
+  for _, run := range runs {
+    fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t\n", run.Day, cell(run, constants.Part1), cell(run, constants.Part2), run.Duration.Round(time.Microsecond))
+  }
+  fmt.Fprintf(tw, "Wall time\t\t\t%s\t\n", wallTime.Round(time.Microsecond))
+  tw.Flush()
+}
+
+// A part that failed shows as "error", and one that wasn't run (e.g. not solved by the day, or not selected) shows as
+// "-". Errors while loading or parsing the input fail every part
+func cell(run selector.DayRun, part constants.PartIndex) string {
+  result, hasResult := run.Result(part)
+  if hasResult { return result.Answer.String() }
+  var dayErr *errs.DayError
+  isDayErr := errors.As(run.Err, &dayErr)
+  if run.Err != nil && (!isDayErr || dayErr.Part == 0 || dayErr.Part == part) { return "error" }
+  return "-"
+}
+
+func Bench(w io.Writer, stats []bench.Stats) {
+  tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+  fmt.Fprint(tw, "Day\tPart\tRuns\tns/op\tallocs/op\tB/op\tPeak heap\t\n")
+  for _, s := range stats {
+    fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t%s\t\n", s.Day, s.Part, s.Iterations, s.NsPerOp, s.AllocsPerOp, s.BytesPerOp, bytes(s.PeakBytes))
+  }
+  tw.Flush()
+}
+
+// Machine readable version of Bench, meant to be saved and compared between commits
+func BenchJSON(w io.Writer, stats []bench.Stats) error {
+  encoder := json.NewEncoder(w)
+  encoder.SetIndent("", "  ")
+  return encoder.Encode(stats)
+}
+
+func bytes(n uint64) string {
+  units := []string{"B", "KiB", "MiB", "GiB"}
+  value := float64(n)
+  unit := 0
+  for value >= 1024 && unit < len(units) - 1 {
+    value /= 1024
+    unit++
+  }
+  if unit == 0 { return fmt.Sprintf("%d B", n) }
+  return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// One line per registered day, with the parts it solves and the inputs the manifest knows of, as version=name.
+// Days with parameters get an extra line for each, with its default value and minimum, and another with their trace
+// categories
+func Days(w io.Writer, manifest *aocio.Manifest) {
+  for _, day := range registry.Days() {
+    entry, _ := registry.Get(day)
+    parts := make([]string, len(entry.Parts))
+    for i, part := range entry.Parts {
+      parts[i] = fmt.Sprintf("%d", part)
+    }
+    inputs := []string{}
+    for _, input := range manifest.Inputs(day) {
+      inputs = append(inputs, fmt.Sprintf("%d=%s", input.Ver, input.Name))
+    }
+    fmt.Fprintf(w, "Day %2d: part %s", day, strings.Join(parts, ", "))
+    if len(inputs) > 0 { fmt.Fprintf(w, "; inputs %s", strings.Join(inputs, ", ")) }
+    fmt.Fprintln(w)
+    for _, param := range entry.Params() {
+      usage := param.Usage
+      if param.Min != 0 { usage += fmt.Sprintf(" (at least %d)", param.Min) }
+      fmt.Fprintf(w, "        %s=%d: %s\n", param.Name, param.Default, usage)
+    }
+    categories := []string{}
+    for _, tracer := range trace.Categories(day) {
+      name := tracer.Name
+      if tracer.Level == trace.Verbose { name += " (verbose)" }
+      categories = append(categories, name)
+    }
+    if len(categories) > 0 { fmt.Fprintf(w, "        trace: %s\n", strings.Join(categories, ", ")) }
+  }
+}
@@ -0,0 +1,146 @@
+// Generates the skeleton of a new day from the templates in templates/: its package, a test wired to the golden answer
+// harness, empty input files with their manifest entries, and its import in days/days.go
+package scaffold
+
+import (
+	"aoc2k24/constants"
+	aocio "aoc2k24/io"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+var templates = template.Must(template.ParseFS(templateFiles, "templates/*.tmpl"))
+
+// Names of the constants.DayIndex constants, by day
+var dayConstants = []string{"", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten", "Eleven",
+  "Twelve", "Thirteen", "Fourteen", "Fifteen", "Sixteen", "Seventeen", "Eighteen", "Nineteen", "Twenty", "TwentyOne",
+  "TwentyTwo", "TwentyThree", "TwentyFour", "TwentyFive"}
+
+// Matches the import of a day in days/days.go, capturing the day
+var dayImportPattern = regexp.MustCompile(`^\s*_ "aoc2k24/d(\d+)"$`)
+
+// Matches the line opening the inputs of a day in the manifest, capturing the day
+var manifestDayPattern = regexp.MustCompile(`^\s*"(\d+)": \[$`)
+
+type templateData struct {
+  Day constants.DayIndex
+  Const string
+}
+
+// Creates every file of the new day under the module root and the inputs directory, and returns the paths of those
+// created or updated. Input files that already exist (e.g. fetched ones) are kept, but the day's package must not
+// exist yet
+func Generate(root string, inputsDir string, day constants.DayIndex) ([]string, error) {
+  if day < 1 || int(day) >= len(dayConstants) { return nil, fmt.Errorf("invalid day %d, expected a number between 1 and 25", day) }
+  data := templateData{day, dayConstants[day]}
+  packageDir := filepath.Join(root, fmt.Sprintf("d%d", day))
+  _, err := os.Stat(packageDir)
+  if err == nil { return nil, fmt.Errorf("%s already exists", packageDir) }
+  err = os.Mkdir(packageDir, 0755)
+  if err != nil { return nil, err }
+
+  created := []string{}
+  sources := []struct{ template, file string }{{"day.go.tmpl", "d%d.go"}, {"day_test.go.tmpl", "d%d_test.go"}}
+  for _, source := range sources {
+    path := filepath.Join(packageDir, fmt.Sprintf(source.file, day))
+    err := writeTemplate(path, source.template, data)
+    if err != nil { return created, err }
+    created = append(created, path)
+  }
+  for ver := range constants.VersionIndex(2) {
+    path := aocio.FilePath(inputsDir, day, ver)
+    file, err := os.OpenFile(path, os.O_CREATE | os.O_EXCL | os.O_WRONLY, 0644)
+    if errors.Is(err, os.ErrExist) { continue }
+    if err != nil { return created, err }
+    file.Close()
+    created = append(created, path)
+  }
+
+  manifestPath := filepath.Join(inputsDir, aocio.ManifestFile)
+  err = addToManifest(manifestPath, data)
+  if err != nil { return created, err }
+  daysPath := filepath.Join(root, "days", "days.go")
+  err = addImport(daysPath, day)
+  if err != nil { return created, err }
+  return append(created, manifestPath, daysPath), nil
+}
+
+func writeTemplate(path string, name string, data templateData) error {
+  var content bytes.Buffer
+  err := templates.ExecuteTemplate(&content, name, data)
+  if err != nil { return err }
+  return os.WriteFile(path, content.Bytes(), 0644)
+}
+
+// Adds the day's inputs to the manifest, keeping days in order. The manifest is laid out by hand with one input per line,
+// which re-encoding it would lose, so the entries are inserted as text
+func addToManifest(path string, data templateData) error {
+  var entry bytes.Buffer
+  err := templates.ExecuteTemplate(&entry, "manifest.json.tmpl", data)
+  if err != nil { return err }
+  entryLines := strings.Split(strings.TrimSuffix(entry.String(), "\n"), "\n")
+
+  content, err := os.ReadFile(path)
+  if errors.Is(err, os.ErrNotExist) {
+    content, err = []byte("{\n}\n"), nil
+  }
+  if err != nil { return err }
+  lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+  // Days come right after "{" and every one but the last ends with "],"
+  insertAt := slices.Index(lines, "}")
+  if insertAt == -1 { return fmt.Errorf("%s: expected a closing } on a line of its own", path) }
+  for i, line := range lines {
+    match := manifestDayPattern.FindStringSubmatch(line)
+    if match == nil { continue }
+    existing, _ := strconv.Atoi(match[1])
+    if existing == int(data.Day) { return fmt.Errorf("%s already lists day %d", path, data.Day) }
+    if existing > int(data.Day) {
+      insertAt = i
+      break
+    }
+  }
+  if lines[insertAt] == "}" {
+    if insertAt > 1 { lines[insertAt - 1] += "," }
+  } else {
+    entryLines[len(entryLines) - 1] += ","
+  }
+  lines = slices.Insert(lines, insertAt, entryLines...)
+  updated := []byte(strings.Join(lines, "\n") + "\n")
+  if !json.Valid(updated) { return fmt.Errorf("%s: adding day %d would leave it invalid", path, data.Day) }
+  return os.WriteFile(path, updated, 0644)
+}
+
+// Imports the day's package in days.go, keeping days in order, so it registers its solver
+func addImport(path string, day constants.DayIndex) error {
+  content, err := os.ReadFile(path)
+  if err != nil { return err }
+  lines := strings.Split(string(content), "\n")
+  insertAt := -1
+  for i, line := range lines {
+    match := dayImportPattern.FindStringSubmatch(line)
+    if match == nil { continue }
+    existing, _ := strconv.Atoi(match[1])
+    if existing == int(day) { return fmt.Errorf("%s already imports day %d", path, day) }
+    if existing > int(day) {
+      insertAt = i
+      break
+    }
+    insertAt = i + 1
+  }
+  if insertAt == -1 { return fmt.Errorf("%s: no day imports found", path) }
+  lines = slices.Insert(lines, insertAt, fmt.Sprintf("\t_ \"aoc2k24/d%d\"", day))
+  return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
@@ -0,0 +1,100 @@
+package scaffold_test
+
+import (
+	"aoc2k24/scaffold"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const daysFile = `// Imports every day package
+package days
+
+import (
+	_ "aoc2k24/d1"
+	_ "aoc2k24/d25"
+)
+`
+
+const manifestFile = `{
+  "1": [
+    {"version": 0, "name": "full", "description": "Puzzle input", "part1": "1"}
+  ],
+  "25": [
+    {"version": 0, "name": "full", "description": "Puzzle input"}
+  ]
+}
+`
+
+// A module root with just what the generator edits: days/days.go and the manifest
+func newRoot(t *testing.T) (string, string) {
+  root := t.TempDir()
+  inputsDir := filepath.Join(root, "files")
+  for path, content := range map[string]string{"days/days.go": daysFile, "files/manifest.json": manifestFile} {
+    path = filepath.Join(root, path)
+    err := os.MkdirAll(filepath.Dir(path), 0755)
+    if err != nil { t.Fatal(err) }
+    err = os.WriteFile(path, []byte(content), 0644)
+    if err != nil { t.Fatal(err) }
+  }
+  return root, inputsDir
+}
+
+func read(t *testing.T, path string) string {
+  content, err := os.ReadFile(path)
+  if err != nil { t.Fatal(err) }
+  return string(content)
+}
+
+func TestGenerate(t *testing.T) {
+  root, inputsDir := newRoot(t)
+  // A fetched input has to be kept as it is
+  err := os.WriteFile(filepath.Join(inputsDir, "20-0.txt"), []byte("fetched\n"), 0644)
+  if err != nil { t.Fatal(err) }
+  _, err = scaffold.Generate(root, inputsDir, 20)
+  if err != nil { t.Fatal(err) }
+
+  for _, name := range []string{"d20/d20.go", "d20/d20_test.go"} {
+    source := read(t, filepath.Join(root, name))
+    _, err := parser.ParseFile(token.NewFileSet(), name, source, 0)
+    if err != nil { t.Errorf("generated %s doesn't parse: %v", name, err) }
+    if !strings.Contains(source, "constants.Twenty") { t.Errorf("generated %s doesn't refer to constants.Twenty", name) }
+  }
+  if read(t, filepath.Join(inputsDir, "20-0.txt")) != "fetched\n" { t.Error("fetched input was overwritten") }
+  if read(t, filepath.Join(inputsDir, "20-1.txt")) != "" { t.Error("example input isn't empty") }
+
+  days := read(t, filepath.Join(root, "days/days.go"))
+  if !strings.Contains(days, "\t_ \"aoc2k24/d1\"\n\t_ \"aoc2k24/d20\"\n\t_ \"aoc2k24/d25\"\n") { t.Errorf("day 20 not imported in order:\n%s", days) }
+
+  manifest := read(t, filepath.Join(inputsDir, "manifest.json"))
+  byDay := make(map[string][]map[string]any)
+  err = json.Unmarshal([]byte(manifest), &byDay)
+  if err != nil { t.Fatalf("manifest is no longer valid: %v\n%s", err, manifest) }
+  if len(byDay["20"]) != 2 { t.Errorf("expected 2 inputs for day 20, got %v", byDay["20"]) }
+  if strings.Index(manifest, `"1": [`) > strings.Index(manifest, `"20": [`) || strings.Index(manifest, `"20": [`) > strings.Index(manifest, `"25": [`) {
+    t.Errorf("day 20 not listed in order:\n%s", manifest)
+  }
+
+  _, err = scaffold.Generate(root, inputsDir, 20)
+  if err == nil { t.Error("generating an existing day again didn't fail") }
+}
+
+func TestGenerateWithoutManifest(t *testing.T) {
+  root, inputsDir := newRoot(t)
+  err := os.RemoveAll(filepath.Join(inputsDir, "manifest.json"))
+  if err != nil { t.Fatal(err) }
+  _, err = scaffold.Generate(root, inputsDir, 24)
+  if err != nil { t.Fatal(err) }
+  _, err = scaffold.Generate(root, inputsDir, 26)
+  if err == nil { t.Error("generating day 26 didn't fail") }
+
+  // Without a manifest, a new one only lists the day
+  byDay := make(map[string][]map[string]any)
+  err = json.Unmarshal([]byte(read(t, filepath.Join(inputsDir, "manifest.json"))), &byDay)
+  if err != nil { t.Fatal(err) }
+  if len(byDay) != 1 || len(byDay["24"]) != 2 { t.Errorf("unexpected manifest %v", byDay) }
+}
@@ -0,0 +1,42 @@
+package search
+
+import "container/heap"
+
+// Min-priority queue backed by a binary heap. Values with the same priority come out in no particular order
+type Queue[T any] struct {
+  items queueItems[T]
+}
+
+type queueItem[T any] struct {
+  value T
+  priority int
+}
+
+func (q *Queue[T]) Push(value T, priority int) {
+  heap.Push(&q.items, queueItem[T]{value, priority})
+}
+
+// Removes the value with the lowest priority. Panics if the queue is empty
+func (q *Queue[T]) Pop() (T, int) {
+  item := heap.Pop(&q.items).(queueItem[T])
+  return item.value, item.priority
+}
+
+func (q *Queue[T]) Len() int {
+  return len(q.items)
+}
+
+// Implements heap.Interface, which Queue wraps so its users don't have to deal with any
+type queueItems[T any] []queueItem[T]
+
+func (q queueItems[T]) Len() int { return len(q) }
+func (q queueItems[T]) Less(a, b int) bool { return q[a].priority < q[b].priority }
+func (q queueItems[T]) Swap(a, b int) { q[a], q[b] = q[b], q[a] }
+func (q *queueItems[T]) Push(item any) { *q = append(*q, item.(queueItem[T])) }
+
+func (q *queueItems[T]) Pop() any {
+  old := *q
+  item := old[len(old) - 1]
+  *q = old[:len(old) - 1]
+  return item
+}
@@ -0,0 +1,138 @@
+// Graph searches over nodes given by a neighbour function: BFS, Dijkstra and A*. Every search keeps all the
+// predecessors of each node on its shortest paths, so besides one shortest path it can tell every node lying on any of
+// them
+package search
+
+import (
+	"iter"
+	"slices"
+)
+
+// Neighbours of a node in an unweighted graph
+type Neighbours[N comparable] func(n N) iter.Seq[N]
+
+// Neighbours of a node in a weighted graph, each with the cost of moving to it. Costs can't be negative
+type WeightedNeighbours[N comparable] func(n N) iter.Seq2[N, int]
+
+// Whether the node is a goal of the search. A nil one never matches, so the whole reachable graph is explored
+type Goal[N comparable] func(n N) bool
+
+type Result[N comparable] struct {
+  Start N
+  // Cost of the shortest path from the start to every node reached
+  Dist map[N]int
+  // The nodes preceding each node on its shortest paths. The start has none
+  Prev map[N][]N
+  // Goals reached at the lowest cost, empty if there were none or no goal was reached
+  Goals []N
+}
+
+// Breadth first search, where every move costs 1. It stops once every goal at the lowest distance has been reached
+func BFS[N comparable](start N, neighbours Neighbours[N], isGoal Goal[N]) *Result[N] {
+  result := newResult(start)
+  queue := []N{start}
+  for len(queue) > 0 {
+    node := queue[0]
+    queue = queue[1:]
+    dist := result.Dist[node]
+    if result.isPastGoals(dist) { break }
+    if isGoal != nil && isGoal(node) {
+      result.Goals = append(result.Goals, node)
+      continue
+    }
+    for next := range neighbours(node) {
+      nextDist, isReached := result.Dist[next]
+      if !isReached {
+        result.Dist[next] = dist + 1
+        result.Prev[next] = []N{node}
+        queue = append(queue, next)
+      } else if nextDist == dist + 1 {
+        result.Prev[next] = append(result.Prev[next], node)
+      }
+    }
+  }
+  return result
+}
+
+// A* without a heuristic, for graphs where there is no good estimate of the cost left
+func Dijkstra[N comparable](start N, neighbours WeightedNeighbours[N], isGoal Goal[N]) *Result[N] {
+  return AStar(start, neighbours, func(N) int { return 0 }, isGoal)
+}
+
+// Dijkstra guided by a heuristic estimating the cost left to a goal. The heuristic has to be consistent (never drop by
+// more than the cost of a move, and be 0 on goals), otherwise the costs found may not be the lowest
+func AStar[N comparable](start N, neighbours WeightedNeighbours[N], heuristic func(n N) int, isGoal Goal[N]) *Result[N] {
+  result := newResult(start)
+  done := make(map[N]struct{})
+  queue := Queue[N]{}
+  queue.Push(start, heuristic(start))
+  for queue.Len() > 0 {
+    node, estimate := queue.Pop()
+    if _, isDone := done[node]; isDone { continue }
+    done[node] = struct{}{}
+    if result.isPastGoals(estimate) { break }
+    dist := result.Dist[node]
+    if isGoal != nil && isGoal(node) {
+      result.Goals = append(result.Goals, node)
+      continue
+    }
+    for next, cost := range neighbours(node) {
+      nextDist, isReached := result.Dist[next]
+      if !isReached || dist + cost < nextDist {
+        result.Dist[next] = dist + cost
+        result.Prev[next] = []N{node}
+        queue.Push(next, dist + cost + heuristic(next))
+      } else if dist + cost == nextDist && !slices.Contains(result.Prev[next], node) {
+        result.Prev[next] = append(result.Prev[next], node)
+      }
+    }
+  }
+  return result
+}
+
+func newResult[N comparable](start N) *Result[N] {
+  return &Result[N]{start, map[N]int{start: 0}, make(map[N][]N), nil}
+}
+
+// Nodes whose (estimated) cost exceeds that of the goals found can't lead to another goal at the same cost
+func (r *Result[N]) isPastGoals(cost int) bool {
+  return len(r.Goals) > 0 && cost > r.Dist[r.Goals[0]]
+}
+
+func (r *Result[N]) Found() bool {
+  return len(r.Goals) > 0
+}
+
+// Cost of reaching the goals. Only meaningful if some goal was found
+func (r *Result[N]) Cost() int {
+  return r.Dist[r.Goals[0]]
+}
+
+// One shortest path from the start to the node, both included. Nil if the node wasn't reached
+func (r *Result[N]) Path(to N) []N {
+  if _, isReached := r.Dist[to]; !isReached { return nil }
+  path := []N{to}
+  for node := to; node != r.Start; {
+    node = r.Prev[node][0]
+    path = append(path, node)
+  }
+  slices.Reverse(path)
+  return path
+}
+
+// Every node lying on any shortest path from the start to any of the given nodes, those included
+func (r *Result[N]) PathNodes(to ...N) map[N]struct{} {
+  nodes := make(map[N]struct{})
+  pending := []N{}
+  for _, node := range to {
+    if _, isReached := r.Dist[node]; isReached { pending = append(pending, node) }
+  }
+  for len(pending) > 0 {
+    node := pending[len(pending) - 1]
+    pending = pending[:len(pending) - 1]
+    if _, isSeen := nodes[node]; isSeen { continue }
+    nodes[node] = struct{}{}
+    pending = append(pending, r.Prev[node]...)
+  }
+  return nodes
+}
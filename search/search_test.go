@@ -0,0 +1,109 @@
+package search_test
+
+import (
+	"aoc2k24/search"
+	"iter"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// Weighted graph with two paths of cost 4 from a to e, a longer direct edge, and a node f nothing leads to:
+//
+//	a -1-> b -1-> d -2-> e
+//	a -1-> c -1-> d
+//	a -5-> e
+//	f -1-> a
+var edges = map[string]map[string]int{
+  "a": {"b": 1, "c": 1, "e": 5},
+  "b": {"d": 1},
+  "c": {"d": 1},
+  "d": {"e": 2},
+  "f": {"a": 1},
+}
+
+func weighted(n string) iter.Seq2[string, int] {
+  return maps.All(edges[n])
+}
+
+func unweighted(n string) iter.Seq[string] {
+  return maps.Keys(edges[n])
+}
+
+func is(goals ...string) search.Goal[string] {
+  return func(n string) bool { return slices.Contains(goals, n) }
+}
+
+func sorted(nodes map[string]struct{}) []string {
+  return slices.Sorted(maps.Keys(nodes))
+}
+
+func TestDistances(t *testing.T) {
+  results := map[string]*search.Result[string]{
+    "Dijkstra": search.Dijkstra("a", weighted, nil),
+    // Every node is at most 2 moves from e, which costs at least 2
+    "AStar": search.AStar("a", weighted, func(n string) int { return map[string]int{"a": 3, "b": 2, "c": 2, "d": 2}[n] }, nil),
+  }
+  for name, result := range results {
+    expected := map[string]int{"a": 0, "b": 1, "c": 1, "d": 2, "e": 4}
+    if !maps.Equal(result.Dist, expected) { t.Errorf("%s: got distances %v, expected %v", name, result.Dist, expected) }
+  }
+  expected := map[string]int{"a": 0, "b": 1, "c": 1, "d": 2, "e": 1}
+  result := search.BFS("a", unweighted, nil)
+  if !maps.Equal(result.Dist, expected) { t.Errorf("BFS: got distances %v, expected %v", result.Dist, expected) }
+}
+
+func TestEqualCostPredecessors(t *testing.T) {
+  result := search.Dijkstra("a", weighted, is("e"))
+  if !result.Found() || result.Cost() != 4 { t.Fatalf("got cost %d (found: %t), expected 4", result.Cost(), result.Found()) }
+  prev := slices.Sorted(slices.Values(result.Prev["d"]))
+  if !slices.Equal(prev, []string{"b", "c"}) { t.Errorf("got predecessors %v of d, expected b and c", prev) }
+  if !slices.Equal(result.Prev["e"], []string{"d"}) { t.Errorf("got predecessors %v of e, expected only d", result.Prev["e"]) }
+  path := result.Path("e")
+  if len(path) != 4 || path[0] != "a" || path[3] != "e" { t.Errorf("got path %v, expected a, b or c, d, e", path) }
+  nodes := sorted(result.PathNodes("e"))
+  if !slices.Equal(nodes, []string{"a", "b", "c", "d", "e"}) { t.Errorf("got path nodes %v", nodes) }
+
+  // In moves, b, c and e are all at 1 from a, and d is reached from both b and c
+  bfs := search.BFS("a", unweighted, nil)
+  prev = slices.Sorted(slices.Values(bfs.Prev["d"]))
+  if !slices.Equal(prev, []string{"b", "c"}) { t.Errorf("BFS: got predecessors %v of d, expected b and c", prev) }
+}
+
+func TestPathNodesOfSeveralGoals(t *testing.T) {
+  result := search.BFS("a", unweighted, is("b", "c", "d"))
+  goals := slices.Sorted(slices.Values(result.Goals))
+  if !slices.Equal(goals, []string{"b", "c"}) { t.Errorf("got goals %v, expected b and c, d being farther", goals) }
+  nodes := sorted(result.PathNodes(result.Goals...))
+  if !slices.Equal(nodes, []string{"a", "b", "c"}) { t.Errorf("got path nodes %v, expected a, b and c", nodes) }
+  // Nodes that weren't reached add nothing
+  nodes = sorted(result.PathNodes("b", "f"))
+  if !slices.Equal(nodes, []string{"a", "b"}) { t.Errorf("got path nodes %v, expected a and b", nodes) }
+}
+
+func TestUnreachable(t *testing.T) {
+  for name, result := range map[string]*search.Result[string]{
+    "BFS": search.BFS("a", unweighted, is("f")),
+    "Dijkstra": search.Dijkstra("a", weighted, is("f")),
+  } {
+    if result.Found() { t.Errorf("%s: found a goal nothing leads to", name) }
+    if len(result.Path("f")) != 0 { t.Errorf("%s: got path %v to an unreachable node", name, result.Path("f")) }
+    if len(result.PathNodes("f")) != 0 { t.Errorf("%s: got path nodes %v of an unreachable node", name, result.PathNodes("f")) }
+    // Without a goal to stop at, the whole reachable graph was explored
+    if len(result.Dist) != 5 { t.Errorf("%s: reached %d nodes, expected 5", name, len(result.Dist)) }
+  }
+}
+
+func TestQueueOrder(t *testing.T) {
+  queue := search.Queue[string]{}
+  for _, priority := range []int{5, 1, 4, 1, 3, 9, 2, 6} {
+    queue.Push(string(rune('a' + priority)), priority)
+  }
+  priorities := []int{}
+  for queue.Len() > 0 {
+    value, priority := queue.Pop()
+    if value != string(rune('a' + priority)) { t.Errorf("got %s with priority %d", value, priority) }
+    priorities = append(priorities, priority)
+  }
+  if !slices.Equal(priorities, []int{1, 1, 2, 3, 4, 5, 6, 9}) { t.Errorf("got priorities %v in that order", priorities) }
+}
@@ -0,0 +1,52 @@
+package selector
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/registry"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Parses the -day flag. Accepts "all", a single day ("5"), a range ("1-10") or a comma separated list of those ("1,3,5-7")
+// A single day is kept even if not registered, so that asking for it reports it as not implemented. Ranges and "all" only
+// expand to registered days
+func ParseDays(spec string) ([]constants.DayIndex, error) {
+  spec = strings.TrimSpace(spec)
+  if spec == "all" {
+    return registry.Days(), nil
+  }
+  days := []constants.DayIndex{}
+  for _, item := range strings.Split(spec, ",") {
+    item = strings.TrimSpace(item)
+    bounds := strings.Split(item, "-")
+    if len(bounds) == 1 {
+      day, err := parseDay(item)
+      if err != nil { return nil, err }
+      days = append(days, day)
+      continue
+    }
+    if len(bounds) != 2 { return nil, fmt.Errorf("invalid day range %q", item) }
+    from, err := parseDay(bounds[0])
+    if err != nil { return nil, err }
+    to, err := parseDay(bounds[1])
+    if err != nil { return nil, err }
+    if from > to { return nil, fmt.Errorf("invalid day range %q, start is after end", item) }
+    for _, day := range registry.Days() {
+      if day >= from && day <= to {
+        days = append(days, day)
+      }
+    }
+  }
+  slices.Sort(days)
+  return slices.Compact(days), nil
+}
+
+func parseDay(value string) (constants.DayIndex, error) {
+  day, err := strconv.Atoi(strings.TrimSpace(value))
+  if err != nil || day < 1 || day > 25 {
+    return 0, fmt.Errorf("invalid day %q, expected a number between 1 and 25", value)
+  }
+  return constants.DayIndex(day), nil
+}
@@ -0,0 +1,75 @@
+package selector
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/io"
+	"aoc2k24/registry"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// A -param value. Day is 0 when the parameter applies to every selected day that declares it
+type ParamOverride struct {
+  Day constants.DayIndex
+  Name string
+  Value int
+}
+
+// Collects every -param given in the command line, each one as [day.]name=value
+type ParamFlags []ParamOverride
+
+func (p *ParamFlags) String() string {
+  specs := make([]string, len(*p))
+  for i, override := range *p {
+    specs[i] = fmt.Sprintf("%s=%d", override.Name, override.Value)
+    if override.Day != 0 { specs[i] = fmt.Sprintf("%d.%s", override.Day, specs[i]) }
+  }
+  return strings.Join(specs, ",")
+}
+
+func (p *ParamFlags) Set(spec string) error {
+  name, valueStr, hasValue := strings.Cut(spec, "=")
+  if !hasValue { return fmt.Errorf("expected [day.]name=value, got %q", spec) }
+  value, err := strconv.Atoi(valueStr)
+  if err != nil { return fmt.Errorf("invalid value for %s: %q", name, valueStr) }
+  override := ParamOverride{Name: name, Value: value}
+  dayStr, paramName, hasDay := strings.Cut(name, ".")
+  if hasDay {
+    override.Day, err = parseDay(dayStr)
+    if err != nil { return err }
+    override.Name = paramName
+  }
+  *p = append(*p, override)
+  return nil
+}
+
+// Applies the overrides on top of the params the manifest has for each input. The manifest's expected answers don't
+// hold anymore for an overridden input, so they're dropped. An override that none of the inputs' days declare is an
+// error, since it would be silently ignored otherwise, and so is one below the minimum of the parameter
+func ApplyParams(inputs []io.InputInfo, overrides ParamFlags) ([]io.InputInfo, error) {
+  applied := make([]io.InputInfo, len(inputs))
+  isUsed := make([]bool, len(overrides))
+  for i, input := range inputs {
+    input.Params = maps.Clone(input.Params)
+    entry, isRegistered := registry.Get(input.Day)
+    for j, override := range overrides {
+      if override.Day != 0 && override.Day != input.Day { continue }
+      if !isRegistered { continue }
+      param, isDeclared := entry.Param(override.Name)
+      if !isDeclared { continue }
+      err := param.Check(override.Value)
+      if err != nil { return nil, fmt.Errorf("day %d: %w", input.Day, err) }
+      if input.Params == nil { input.Params = make(map[string]int) }
+      input.Params[override.Name] = override.Value
+      input.Part1, input.Part2 = "", ""
+      isUsed[j] = true
+    }
+    applied[i] = input
+  }
+  for j, override := range overrides {
+    if !isUsed[j] { return nil, fmt.Errorf("no selected day has a parameter named %q", override.Name) }
+  }
+  return applied, nil
+}
@@ -0,0 +1,34 @@
+package selector
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/registry"
+	"fmt"
+)
+
+// Parses a -part value: 1, 2 or both. Both gives nil, which stands for every part the day solves
+func ParseParts(spec string) ([]constants.PartIndex, error) {
+  switch spec {
+  case "both":
+    return nil, nil
+  case "1":
+    return []constants.PartIndex{constants.Part1}, nil
+  case "2":
+    return []constants.PartIndex{constants.Part2}, nil
+  default:
+    return nil, fmt.Errorf("invalid part %q, expected 1, 2 or both", spec)
+  }
+}
+
+// Which of the requested parts get run for the day. Asking for a part the day doesn't solve is an error, while
+// asking for every part (nil) just runs those it does solve
+func PartsToRun(entry registry.Entry, ver constants.VersionIndex, parts []constants.PartIndex) ([]constants.PartIndex, error) {
+  if parts == nil { return entry.Parts, nil }
+  for _, part := range parts {
+    if !entry.HasPart(part) {
+      return nil, &errs.DayError{Day: entry.Day, Ver: ver, Part: part, Err: errs.ErrDayNotImplemented}
+    }
+  }
+  return parts, nil
+}
@@ -2,69 +2,151 @@ package selector
 
 import (
 	"aoc2k24/constants"
-	"aoc2k24/d1"
-  "aoc2k24/d2"
-  "aoc2k24/d3"
-  "aoc2k24/d4"
-  "aoc2k24/d5"
-  "aoc2k24/d6"
-  "aoc2k24/d7"
-  "aoc2k24/d8"
-  "aoc2k24/d9"
-  "aoc2k24/d10"
-  "aoc2k24/d11"
-  "aoc2k24/d12"
-  "aoc2k24/d13"
-  "aoc2k24/d14"
-  "aoc2k24/d15"
-  "aoc2k24/d16"
-  "aoc2k24/d17"
-  "aoc2k24/d18"
-  "aoc2k24/d19"
-	"fmt"
+	"aoc2k24/errs"
+	"aoc2k24/io"
+	"aoc2k24/registry"
+	"aoc2k24/trace"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
 )
 
-func RunDay(day constants.DayIndex, ver constants.VersionIndex) {
-  switch day {
-    case constants.One:
-      d1.Init(ver)
-    case constants.Two:
-      d2.Init(ver)
-    case constants.Three:
-      d3.Init(ver)
-    case constants.Four:
-      d4.Init(ver)
-    case constants.Five:
-      d5.Init(ver)
-    case constants.Six:
-      d6.Init(ver)
-    case constants.Seven:
-      d7.Init(ver)
-    case constants.Eight:
-      d8.Init(ver)
-    case constants.Nine:
-      d9.Init(ver)
-    case constants.Ten:
-      d10.Init(ver)
-    case constants.Eleven:
-      d11.Init(ver)
-    case constants.Twelve:
-      d12.Init(ver)
-    case constants.Thirteen:
-      d13.Init(ver)
-    case constants.Fourteen:
-      d14.Init(ver)
-    case constants.Fifteen:
-      d15.Init(ver)
-    case constants.Sixteen:
-      d16.Init(ver)
-    case constants.Seventeen:
-      d17.Init(ver)
-    case constants.Eighteen:
-      d18.Init(ver)
-    case constants.Nineteen:
-      d19.Init(ver)
-    default:
-      panic(fmt.Sprintf("Day %d is not present", day))
+// Stacks of the panics recovered while solving, in a category of every day so that e.g. -trace d3 shows those of day 3
+var tracePanics = make(map[constants.DayIndex]*trace.Tracer)
+
+func init() {
+  for day := constants.One; day <= constants.TwentyFive; day++ {
+    tracePanics[day] = trace.New(day, "panics", trace.Debug, "Stack of a panic recovered while solving")
+  }
+}
+
+type Result struct {
+  Day constants.DayIndex
+  Part constants.PartIndex
+  Answer registry.Answer
+  Duration time.Duration
+  // Whatever else the day tells about the answer, see registry.Explainer
+  Extras any
+}
+
+// Outcome of running a whole day. Duration is the wall time, including loading and parsing the input
+type DayRun struct {
+  Day constants.DayIndex
+  Ver constants.VersionIndex
+  Results []Result
+  Err error
+  Duration time.Duration
+}
+
+func (d DayRun) Result(part constants.PartIndex) (Result, bool) {
+  for _, result := range d.Results {
+    if result.Part == part { return result, true }
+  }
+  return Result{}, false
+}
+
+// Runs the given inputs on up to jobs workers at a time, returning their runs in the order of the inputs. Days share no
+// state, so they can run concurrently. A failing (or panicking) day doesn't stop the rest from running, and answers
+// that differ from the expected ones are reported as errors of their day
+func RunDays(inputs []io.InputInfo, parts []constants.PartIndex, jobs int) []DayRun {
+  runs := make([]DayRun, len(inputs))
+  indexes := make(chan int)
+  var workers sync.WaitGroup
+  for range min(max(jobs, 1), len(inputs)) {
+    workers.Add(1)
+    go func() {
+      defer workers.Done()
+      for i := range indexes {
+        runs[i] = runInput(inputs[i], parts)
+      }
+    }()
+  }
+  for i := range inputs {
+    indexes <- i
+  }
+  close(indexes)
+  workers.Wait()
+  return runs
+}
+
+func runInput(input io.InputInfo, parts []constants.PartIndex) DayRun {
+  start := time.Now()
+  results, err := RunDay(input, parts)
+  duration := time.Since(start)
+  err = errors.Join(err, checkAnswers(input, results))
+  return DayRun{input.Day, input.Ver, results, err, duration}
+}
+
+func checkAnswers(input io.InputInfo, results []Result) error {
+  expected := input.Expected()
+  wrong := []error{}
+  for _, result := range results {
+    answer, isExpected := expected[result.Part]
+    if !isExpected || result.Answer.String() == answer { continue }
+    err := errs.WrongAnswer(result.Answer.String(), answer)
+    wrong = append(wrong, &errs.DayError{Day: input.Day, Ver: input.Ver, Part: result.Part, Err: err})
+  }
+  return errors.Join(wrong...)
+}
+
+// Looks up the day and loads the input, without parsing it
+func LoadInput(input io.InputInfo) (registry.Entry, []string, error) {
+  day, ver := input.Day, input.Ver
+  entry, isRegistered := registry.Get(day)
+  if !isRegistered {
+    return entry, nil, &errs.DayError{Day: day, Ver: ver, Err: errs.ErrDayNotImplemented}
+  }
+  lines, err := io.GetLines(input)
+  if (err != nil) {
+    return entry, nil, &errs.DayError{Day: day, Ver: ver, Err: err}
+  }
+  return entry, lines, nil
+}
+
+// Builds a fresh solver for the input, sets its parameters and parses the lines into it
+func NewSolver(entry registry.Entry, input io.InputInfo, lines []string) (registry.Solver, error) {
+  solver := entry.New()
+  err := registry.Configure(solver, input.Params)
+  if err != nil { return nil, err }
+  return solver, solver.Parse(lines)
+}
+
+// Runs the given parts of the day, or every part it solves if nil. On error, the results of the parts that did finish
+// are returned along with it. A panic of the solver is recovered and returned as an error of the part it happened in,
+// and its stack traced
+func RunDay(input io.InputInfo, parts []constants.PartIndex) (results []Result, err error) {
+  day, ver := input.Day, input.Ver
+  // Part being solved, 0 while parsing
+  current := constants.PartIndex(0)
+  defer func() {
+    value := recover()
+    if value == nil { return }
+    err = &errs.DayError{Day: day, Ver: ver, Part: current, Err: errs.Panicked(value)}
+    tracePanics[day].Printf("%v\n%s", err, debug.Stack())
+  }()
+  entry, lines, err := LoadInput(input)
+  if err != nil {
+    return nil, err
+  }
+  parts, err = PartsToRun(entry, ver, parts)
+  if err != nil {
+    return nil, err
+  }
+  solver, err := NewSolver(entry, input, lines)
+  if err != nil {
+    return nil, &errs.DayError{Day: day, Ver: ver, Err: err}
+  }
+  results = make([]Result, 0, len(parts))
+  for _, part := range parts {
+    current = part
+    start := time.Now()
+    answer, err := registry.Solve(solver, part)
+    if err != nil {
+      return results, &errs.DayError{Day: day, Ver: ver, Part: part, Err: err}
+    }
+    duration := time.Since(start)
+    results = append(results, Result{day, part, answer, duration, registry.Extras(solver, part)})
   }
+  return results, nil
 }
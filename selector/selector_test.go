@@ -0,0 +1,142 @@
+package selector_test
+
+import (
+	"aoc2k24/constants"
+	_ "aoc2k24/d17"
+	_ "aoc2k24/d7"
+	"aoc2k24/errs"
+	"aoc2k24/io"
+	"aoc2k24/registry"
+	"aoc2k24/selector"
+	"aoc2k24/trace"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Answers its first line after sleeping that many milliseconds, so that earlier inputs can finish last
+type sleeper struct {
+  delay int
+}
+
+func (s *sleeper) Parse(lines []string) error {
+  var err error
+  s.delay, err = strconv.Atoi(lines[0])
+  return err
+}
+
+func (s *sleeper) Part1() (registry.Answer, error) {
+  time.Sleep(time.Duration(s.delay) * time.Millisecond)
+  return registry.Int(s.delay), nil
+}
+
+func (s *sleeper) Part2() (registry.Answer, error) {
+  var cells []int
+  return registry.Int(cells[s.delay]), nil
+}
+
+func init() {
+  registry.Register(constants.TwentyFive, func() registry.Solver { return &sleeper{} })
+}
+
+func writeInput(t *testing.T, content string) string {
+  path := filepath.Join(t.TempDir(), "input.txt")
+  err := os.WriteFile(path, []byte(content), 0644)
+  if err != nil { t.Fatal(err) }
+  return path
+}
+
+func TestRunDaysKeepsOrder(t *testing.T) {
+  inputs := []io.InputInfo{}
+  for _, delay := range []string{"60", "40", "20", "0"} {
+    inputs = append(inputs, io.InputInfo{Day: constants.TwentyFive, Part1: delay, Path: writeInput(t, delay)})
+  }
+  runs := selector.RunDays(inputs, []constants.PartIndex{constants.Part1}, len(inputs))
+  for i, run := range runs {
+    if run.Err != nil { t.Fatalf("input %d: %v", i, run.Err) }
+    result, _ := run.Result(constants.Part1)
+    if result.Answer.String() != inputs[i].Part1 { t.Errorf("input %d: got %s, expected %s", i, result.Answer, inputs[i].Part1) }
+  }
+}
+
+func TestRunDaysIsolatesPanics(t *testing.T) {
+  panicking := io.InputInfo{Day: constants.TwentyFive, Path: writeInput(t, "1")}
+  example := io.InputInfo{Day: constants.Seventeen, Ver: 1, Part1: "4,6,3,5,6,3,5,2,1,0"}
+  runs := selector.RunDays([]io.InputInfo{panicking, example}, nil, 2)
+
+  var dayErr *errs.DayError
+  if !errors.As(runs[0].Err, &dayErr) || !errors.Is(dayErr, errs.ErrPanicked) || dayErr.Part != constants.Part2 {
+    t.Errorf("expected a panic in part 2, got %v", runs[0].Err)
+  }
+  if strings.Contains(runs[0].Err.Error(), "goroutine") { t.Errorf("the error holds the stack of the panic: %v", runs[0].Err) }
+  if _, isSolved := runs[0].Result(constants.Part1); !isSolved { t.Error("part 1 lost its answer to the panic of part 2") }
+  // Part 2 of the example can't be solved, which has nothing to do with the panic
+  _, isSolved := runs[1].Result(constants.Part1)
+  if !isSolved || errors.Is(runs[1].Err, errs.ErrPanicked) { t.Errorf("day 17 failed along with the panicking day: %v", runs[1].Err) }
+}
+
+// Runs of the same day share nothing, so they give the same answers however many run at once
+func TestRunDaysConcurrently(t *testing.T) {
+  inputs := make([]io.InputInfo, 8)
+  for i := range inputs {
+    inputs[i] = io.InputInfo{Day: constants.Seventeen, Ver: 1, Part1: "4,6,3,5,6,3,5,2,1,0"}
+  }
+  for _, run := range selector.RunDays(inputs, []constants.PartIndex{constants.Part1}, len(inputs)) {
+    if run.Err != nil { t.Error(run.Err) }
+  }
+}
+
+func TestUsePath(t *testing.T) {
+  selected := []io.InputInfo{{Day: constants.Seven, Ver: 2, Name: "full", Description: "Puzzle input", Part1: "1", Part2: "2", Params: map[string]int{"operators": 4}}}
+  for _, path := range []string{"other.txt", io.Stdin} {
+    inputs, err := selector.UsePath(selected, path)
+    if err != nil { t.Fatal(err) }
+    input := inputs[0]
+    if input.Path != path || input.Day != constants.Seven || input.Ver != 2 { t.Errorf("%s: got input %+v", path, input) }
+    // The answers of the selected version don't apply to another input, but its parameters do
+    if input.Part1 != "" || input.Part2 != "" || input.Description != "" { t.Errorf("%s: kept the answers or description of the version", path) }
+    if input.Params["operators"] != 4 { t.Errorf("%s: lost the parameters of the version", path) }
+  }
+  inputs, _ := selector.UsePath(selected, io.Stdin)
+  if inputs[0].Name != "stdin" { t.Errorf("got name %q for stdin", inputs[0].Name) }
+  if selected[0].Part1 != "1" { t.Error("changed the selected input") }
+
+  _, err := selector.UsePath(append(selected, selected[0]), "other.txt")
+  if err == nil { t.Error("used a single path for two inputs") }
+}
+
+// Values below the minimum of a parameter are refused, whether given with -param or by the manifest
+func TestParamMinimum(t *testing.T) {
+  inputs := []io.InputInfo{{Day: constants.Seven, Params: map[string]int{"operators": 2}}}
+  for _, value := range []int{1, 5} {
+    applied, err := selector.ApplyParams(inputs, selector.ParamFlags{{Name: "operators", Value: value}})
+    if err != nil || applied[0].Params["operators"] != value { t.Errorf("operators=%d: got %v, %v", value, applied, err) }
+  }
+  _, err := selector.ApplyParams(inputs, selector.ParamFlags{{Name: "operators", Value: 0}})
+  if err == nil { t.Error("applied operators=0, below the minimum of 1") }
+
+  entry, _ := registry.Get(constants.Seven)
+  _, err = selector.NewSolver(entry, io.InputInfo{Day: constants.Seven, Params: map[string]int{"operators": -1}}, nil)
+  if !errors.Is(err, errs.ErrMalformedInput) { t.Errorf("got %v for operators=-1 in the manifest, expected a malformed input", err) }
+}
+
+// The stack of a panic goes to the panics category of the day instead of the error
+func TestRunDaysTracesPanics(t *testing.T) {
+  var traced bytes.Buffer
+  output := trace.Output()
+  trace.SetOutput(&traced)
+  t.Cleanup(func() { trace.SetOutput(output) })
+  err := trace.Enable("d25:panics")
+  if err != nil { t.Fatal(err) }
+
+  runs := selector.RunDays([]io.InputInfo{{Day: constants.TwentyFive, Path: writeInput(t, "1")}}, nil, 1)
+  if !errors.Is(runs[0].Err, errs.ErrPanicked) { t.Fatalf("expected a panic, got %v", runs[0].Err) }
+  if !strings.Contains(traced.String(), runs[0].Err.Error()) || !strings.Contains(traced.String(), "goroutine") {
+    t.Errorf("expected the error and the stack of the panic in the trace, got\n%s", traced.String())
+  }
+}
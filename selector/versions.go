@@ -0,0 +1,45 @@
+package selector
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/io"
+	"fmt"
+	"strconv"
+)
+
+// Resolves a -v value for each of the days. A number is the same version for all of them, anything else is the name
+// of an input in the manifest, which every day then has to have. Inputs missing from the manifest just have no
+// expected answers
+func ResolveVersions(manifest *io.Manifest, days []constants.DayIndex, spec string) ([]io.InputInfo, error) {
+  inputs := make([]io.InputInfo, 0, len(days))
+  ver, err := strconv.Atoi(spec)
+  if err == nil && ver < 0 {
+    return nil, fmt.Errorf("invalid version %d", ver)
+  }
+  for _, day := range days {
+    if err == nil {
+      input, _ := manifest.Lookup(day, constants.VersionIndex(ver))
+      inputs = append(inputs, input)
+      continue
+    }
+    input, isFound := manifest.Find(day, spec)
+    if !isFound {
+      return nil, fmt.Errorf("day %d has no input named %q", day, spec)
+    }
+    inputs = append(inputs, input)
+  }
+  return inputs, nil
+}
+
+// Reads the single selected input from path (or stdin, given as -) instead of its input file. It keeps the parameters
+// of the selected version, but not its expected answers, since they're unknown for an arbitrary input
+func UsePath(inputs []io.InputInfo, path string) ([]io.InputInfo, error) {
+  if len(inputs) != 1 { return nil, fmt.Errorf("an input path needs a single day, got %d", len(inputs)) }
+  input := inputs[0]
+  input.Name = path
+  if path == io.Stdin { input.Name = "stdin" }
+  input.Description = ""
+  input.Part1, input.Part2 = "", ""
+  input.Path = path
+  return []io.InputInfo{input}, nil
+}
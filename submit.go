@@ -0,0 +1,109 @@
+package main
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/fetch"
+	"aoc2k24/io"
+	"aoc2k24/selector"
+	"aoc2k24/submit"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Solves the full input of the selected days and submits the answers that aren't known yet, recording every attempt
+// in the ledger
+func runSubmit(args []string) int {
+  flags := flag.NewFlagSet("submit", flag.ExitOnError)
+  dayParam := flags.String("day", "", "The days to submit: a single day (5), a range (1-10) or a comma separated list (1,3,5-7)")
+  partParam := flags.String("part", "both", "The part to submit: 1, 2 or both")
+  inputsParam := flags.String("inputs", "", io.InputsFlagUsage("Directory holding the puzzle inputs and the " + submit.LedgerFile))
+  sessionParam := flags.String("session", os.Getenv(fetch.SessionEnvVar), fmt.Sprintf("Session token, the value of the session cookie of the site. Defaults to $%s", fetch.SessionEnvVar))
+  urlParam := flags.String("url", fetch.DefaultBaseURL, "Base URL of the site, to submit to a local stand-in instead")
+  flags.Parse(args)
+
+  if *dayParam == "" {
+    fmt.Fprintln(os.Stderr, "Error: -day is required")
+    return errs.ExitUsage
+  }
+  days, err := selector.ParseDays(*dayParam)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitUsage
+  }
+  parts, err := selector.ParseParts(*partParam)
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitUsage
+  }
+  io.SetInputRoot(*inputsParam)
+  manifest, err := io.LoadManifest()
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitCode(err)
+  }
+  ledger, err := submit.LoadLedger()
+  if err != nil {
+    fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+    return errs.ExitCode(err)
+  }
+  return submitDays(manifest, ledger, days, parts, *sessionParam, *urlParam)
+}
+
+func submitDays(manifest *io.Manifest, ledger *submit.Ledger, days []constants.DayIndex, parts []constants.PartIndex, session string, baseURL string) int {
+  client := fetch.NewClient(session)
+  client.BaseURL = baseURL
+  // Every error is reported, but the exit code is the one of the first failing part
+  exitCode := errs.ExitOK
+  for _, day := range days {
+    input, _ := manifest.Lookup(day, 0)
+    input = ledger.FillExpected([]io.InputInfo{input})[0]
+    results, err := selector.RunDay(input, parts)
+    if err != nil {
+      fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+      if exitCode == errs.ExitOK { exitCode = errs.ExitCode(err) }
+    }
+    for _, result := range results {
+      err := submitResult(client, ledger, input, result)
+      if err != nil {
+        err = &errs.DayError{Day: day, Ver: input.Ver, Part: result.Part, Err: err}
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        if exitCode == errs.ExitOK { exitCode = errs.ExitCode(err) }
+      }
+    }
+  }
+  return exitCode
+}
+
+// Submits the answer unless it's already known to be right or wrong. Answers the site finds wrong are returned as
+// errs.ErrWrongAnswer errors, and those it doesn't check (too soon, wrong level) as errs.ErrRequestFailed ones
+func submitResult(client *fetch.Client, ledger *submit.Ledger, input io.InputInfo, result selector.Result) error {
+  answer := result.Answer.String()
+  expected, isKnown := input.Expected()[result.Part]
+  if isKnown {
+    if answer != expected { return errs.WrongAnswer(answer, expected) }
+    fmt.Printf("Day %d, part %d: %s is already known to be right\n", result.Day, result.Part, answer)
+    return nil
+  }
+  reason := ledger.Rejection(result.Day, result.Part, answer)
+  if reason != "" { return fmt.Errorf("%w: not submitting, %s", errs.ErrWrongAnswer, reason) }
+
+  response, err := submit.Submit(client, result.Day, result.Part, answer)
+  if err != nil { return err }
+  ledger.Record(submit.Attempt{Day: result.Day, Part: result.Part, Answer: answer, Outcome: response.Outcome, Time: time.Now()})
+  err = ledger.Save()
+  if err != nil { return err }
+  fmt.Printf("Day %d, part %d: %s is %s\n", result.Day, result.Part, answer, response.Outcome)
+  switch {
+  case response.Outcome == submit.Correct:
+    return nil
+  case response.Outcome.IsWrong():
+    return fmt.Errorf("%w: %s", errs.ErrWrongAnswer, response.Message)
+  case response.Outcome == submit.Wait:
+    return fmt.Errorf("%w: answer not checked, wait %s before submitting again", errs.ErrRequestFailed, response.Wait)
+  default:
+    return fmt.Errorf("%w: answer not checked: %s", errs.ErrRequestFailed, response.Message)
+  }
+}
@@ -0,0 +1,101 @@
+package submit
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	aocio "aoc2k24/io"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Lives in the inputs directory next to the inputs, since like them it belongs to one user
+const LedgerFile = "ledger.json"
+
+// One answer submitted to the site and what it made of it
+type Attempt struct {
+  Day constants.DayIndex `json:"day"`
+  Part constants.PartIndex `json:"part"`
+  Answer string `json:"answer"`
+  Outcome Outcome `json:"outcome"`
+  Time time.Time `json:"time"`
+}
+
+// Every attempt ever made, oldest first. The site only says whether an answer is right, so this is what lets later
+// runs check their answers and avoid submitting the same wrong one twice
+type Ledger struct {
+  path string
+  Attempts []Attempt
+}
+
+// Reads the ledger from the inputs directory. A missing ledger isn't an error, just an empty one
+func LoadLedger() (*Ledger, error) {
+  ledger := &Ledger{}
+  path, err := aocio.ResolveFile(LedgerFile)
+  if errors.Is(err, errs.ErrInputNotFound) { return ledger, nil }
+  if err != nil { return nil, err }
+  ledger.path = path
+  data, err := os.ReadFile(path)
+  if err != nil { return nil, err }
+  err = json.Unmarshal(data, &ledger.Attempts)
+  if err != nil { return nil, errs.Malformed("%s: %v", LedgerFile, err) }
+  return ledger, nil
+}
+
+// Writes the ledger back where it was read from, or to the inputs directory if it didn't exist yet
+func (l *Ledger) Save() error {
+  if l.path == "" {
+    dir, err := aocio.InputsDir()
+    if err != nil { return err }
+    l.path = filepath.Join(dir, LedgerFile)
+  }
+  data, err := json.MarshalIndent(l.Attempts, "", "  ")
+  if err != nil { return err }
+  return os.WriteFile(l.path, append(data, '\n'), 0644)
+}
+
+func (l *Ledger) Record(attempt Attempt) {
+  l.Attempts = append(l.Attempts, attempt)
+}
+
+// The answer the site accepted for the part, if any
+func (l *Ledger) Accepted(day constants.DayIndex, part constants.PartIndex) (string, bool) {
+  for _, attempt := range l.Attempts {
+    if attempt.Day == day && attempt.Part == part && attempt.Outcome == Correct { return attempt.Answer, true }
+  }
+  return "", false
+}
+
+// Why past attempts rule the answer out: it was already found wrong, or it's a number beyond one found too high or too
+// low. Empty if the answer may still be right
+func (l *Ledger) Rejection(day constants.DayIndex, part constants.PartIndex, answer string) string {
+  value, err := strconv.ParseInt(answer, 10, 64)
+  isNumber := err == nil
+  for _, attempt := range l.Attempts {
+    if attempt.Day != day || attempt.Part != part || !attempt.Outcome.IsWrong() { continue }
+    if attempt.Answer == answer { return fmt.Sprintf("%s was already submitted and found %s", answer, attempt.Outcome) }
+    bound, err := strconv.ParseInt(attempt.Answer, 10, 64)
+    if !isNumber || err != nil { continue }
+    if attempt.Outcome == TooHigh && value >= bound { return fmt.Sprintf("%s is not below %s, which was found too high", answer, attempt.Answer) }
+    if attempt.Outcome == TooLow && value <= bound { return fmt.Sprintf("%s is not above %s, which was found too low", answer, attempt.Answer) }
+  }
+  return ""
+}
+
+// Fills in the expected answers of the full inputs that the manifest has none for with those the site accepted, so they
+// get checked like the rest
+func (l *Ledger) FillExpected(inputs []aocio.InputInfo) []aocio.InputInfo {
+  filled := make([]aocio.InputInfo, len(inputs))
+  for i, input := range inputs {
+    if input.Ver == 0 && input.Path == "" {
+      if input.Part1 == "" { input.Part1, _ = l.Accepted(input.Day, constants.Part1) }
+      if input.Part2 == "" { input.Part2, _ = l.Accepted(input.Day, constants.Part2) }
+    }
+    filled[i] = input
+  }
+  return filled
+}
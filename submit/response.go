@@ -0,0 +1,76 @@
+package submit
+
+import (
+	"aoc2k24/errs"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// What the site made of a submitted answer
+type Outcome string
+
+const (
+  Correct Outcome = "correct"
+  Wrong Outcome = "wrong"
+  TooHigh Outcome = "too high"
+  TooLow Outcome = "too low"
+  // Submitted too soon after a wrong answer, so it wasn't checked
+  Wait Outcome = "wait"
+  // The part was already solved, or part 1 isn't yet, so there's nothing to check the answer against
+  WrongLevel Outcome = "wrong level"
+)
+
+// Whether the site checked the answer and found it wrong
+func (o Outcome) IsWrong() bool {
+  return o == Wrong || o == TooHigh || o == TooLow
+}
+
+type Response struct {
+  Outcome Outcome
+  // How long until the next answer can be submitted. Only set for Wait
+  Wait time.Duration
+  // The text of the response, without markup
+  Message string
+}
+
+var (
+  articlePattern = regexp.MustCompile(`(?s)<article[^>]*>(.*?)</article>`)
+  tagPattern = regexp.MustCompile(`<[^>]*>`)
+  waitPattern = regexp.MustCompile(`You have (?:(\d+)m )?(\d+)s left to wait`)
+)
+
+// Parses the page the site answers a submission with. The verdict is in its only article, in plain English
+func ParseResponse(page []byte) (Response, error) {
+  match := articlePattern.FindSubmatch(page)
+  if match == nil { return Response{}, fmt.Errorf("%w: response has no message", errs.ErrRequestFailed) }
+  message := html.UnescapeString(tagPattern.ReplaceAllString(string(match[1]), ""))
+  message = strings.Join(strings.Fields(message), " ")
+  response := Response{Message: message}
+  switch {
+  case strings.Contains(message, "That's the right answer"):
+    response.Outcome = Correct
+  case strings.Contains(message, "answer is too high"):
+    response.Outcome = TooHigh
+  case strings.Contains(message, "answer is too low"):
+    response.Outcome = TooLow
+  case strings.Contains(message, "That's not the right answer"):
+    response.Outcome = Wrong
+  case strings.Contains(message, "You gave an answer too recently"):
+    response.Outcome = Wait
+    wait := waitPattern.FindStringSubmatch(message)
+    if wait != nil {
+      minutes, _ := strconv.Atoi(wait[1])
+      seconds, _ := strconv.Atoi(wait[2])
+      response.Wait = time.Duration(minutes) * time.Minute + time.Duration(seconds) * time.Second
+    }
+  case strings.Contains(message, "You don't seem to be solving the right level"):
+    response.Outcome = WrongLevel
+  default:
+    return response, fmt.Errorf("%w: unrecognised response: %s", errs.ErrRequestFailed, message)
+  }
+  return response, nil
+}
@@ -0,0 +1,25 @@
+// Submits answers to the Advent of Code site and keeps a ledger of every attempt, so answers already known to be right
+// are checked locally and those known to be wrong are never submitted again
+package submit
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/fetch"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Posts the answer to the part and parses the site's verdict
+func Submit(client *fetch.Client, day constants.DayIndex, part constants.PartIndex, answer string) (Response, error) {
+  form := url.Values{"level": {strconv.Itoa(int(part))}, "answer": {answer}}
+  urlPath := fmt.Sprintf("/%d/day/%d/answer", fetch.Year, day)
+  request, err := http.NewRequest(http.MethodPost, client.BaseURL + urlPath, strings.NewReader(form.Encode()))
+  if err != nil { return Response{}, err }
+  request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+  page, err := client.Do(request)
+  if err != nil { return Response{}, err }
+  return ParseResponse(page)
+}
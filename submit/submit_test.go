@@ -0,0 +1,142 @@
+package submit_test
+
+import (
+	"aoc2k24/constants"
+	"aoc2k24/errs"
+	"aoc2k24/fetch"
+	aocio "aoc2k24/io"
+	"aoc2k24/submit"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const session = "test-session"
+
+// Pages shaped like those the site answers submissions with
+func page(message string) string {
+  return fmt.Sprintf("<html><body><main>\n<article><p>%s</p></article>\n</main></body></html>", message)
+}
+
+var (
+  rightPage = page(`That's the right answer!  You are <span class="day-success">one gold star</span> closer to finding the Chief Historian. <a href="/2024/day/5#part2">[Continue to Part Two]</a>`)
+  tooHighPage = page(`That's not the right answer; your answer is too high.  If you're stuck, make sure you're using the full input data. Please wait one minute before trying again. <a href="/2024/day/5">[Return to Day 5]</a>`)
+  tooLowPage = page(`That's not the right answer; your answer is too low.  Please wait one minute before trying again. <a href="/2024/day/5">[Return to Day 5]</a>`)
+  wrongPage = page(`That's not the right answer.  If you're stuck, make sure you're using the full input data. <a href="/2024/day/5">[Return to Day 5]</a>`)
+  waitPage = page(`You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 1m 23s left to wait. <a href="/2024/day/5">[Return to Day 5]</a>`)
+  levelPage = page(`You don't seem to be solving the right level.  Did you already complete it? <a href="/2024/day/5">[Return to Day 5]</a>`)
+)
+
+func TestParseResponse(t *testing.T) {
+  tests := []struct {
+    name string
+    page string
+    outcome submit.Outcome
+    wait time.Duration
+  }{
+    {"right", rightPage, submit.Correct, 0},
+    {"too high", tooHighPage, submit.TooHigh, 0},
+    {"too low", tooLowPage, submit.TooLow, 0},
+    {"wrong", wrongPage, submit.Wrong, 0},
+    {"wait", waitPage, submit.Wait, 83 * time.Second},
+    {"wrong level", levelPage, submit.WrongLevel, 0},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      response, err := submit.ParseResponse([]byte(test.page))
+      if err != nil { t.Fatal(err) }
+      if response.Outcome != test.outcome { t.Errorf("expected %s, got %s", test.outcome, response.Outcome) }
+      if response.Wait != test.wait { t.Errorf("expected to wait %s, got %s", test.wait, response.Wait) }
+    })
+  }
+  _, err := submit.ParseResponse([]byte(page("Something else entirely")))
+  if !errors.Is(err, errs.ErrRequestFailed) { t.Errorf("expected an unrecognised response to fail, got %v", err) }
+}
+
+// Stand-in for the site that accepts 42 as the answer to every part, and tells whether other numbers are too high or
+// too low
+func newServer(t *testing.T) *httptest.Server {
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost || r.URL.Path != "/2024/day/5/answer" {
+      http.NotFound(w, r)
+      return
+    }
+    cookie, err := r.Cookie("session")
+    if err != nil || cookie.Value != session {
+      http.Error(w, "Unauthorized", http.StatusBadRequest)
+      return
+    }
+    if r.FormValue("level") != "2" { t.Errorf("expected level 2, got %q", r.FormValue("level")) }
+    answer, err := strconv.Atoi(r.FormValue("answer"))
+    switch {
+    case err != nil:
+      fmt.Fprint(w, wrongPage)
+    case answer > 42:
+      fmt.Fprint(w, tooHighPage)
+    case answer < 42:
+      fmt.Fprint(w, tooLowPage)
+    default:
+      fmt.Fprint(w, rightPage)
+    }
+  }))
+  t.Cleanup(server.Close)
+  return server
+}
+
+func TestSubmit(t *testing.T) {
+  client := fetch.NewClient(session)
+  client.BaseURL = newServer(t).URL
+  client.Interval = 0
+  tests := map[string]submit.Outcome{"42": submit.Correct, "50": submit.TooHigh, "7": submit.TooLow, "abc": submit.Wrong}
+  for answer, outcome := range tests {
+    response, err := submit.Submit(client, 5, constants.Part2, answer)
+    if err != nil { t.Fatal(err) }
+    if response.Outcome != outcome { t.Errorf("answer %s: expected %s, got %s", answer, outcome, response.Outcome) }
+  }
+
+  client.Session = "expired"
+  _, err := submit.Submit(client, 5, constants.Part2, "42")
+  if !errors.Is(err, errs.ErrRequestFailed) { t.Errorf("expected a rejected session to fail, got %v", err) }
+}
+
+func TestLedger(t *testing.T) {
+  aocio.SetInputRoot(t.TempDir())
+  defer aocio.SetInputRoot("")
+  ledger, err := submit.LoadLedger()
+  if err != nil { t.Fatal(err) }
+  if len(ledger.Attempts) != 0 { t.Fatalf("expected a missing ledger to be empty, got %d attempts", len(ledger.Attempts)) }
+  for _, attempt := range []submit.Attempt{
+    {Day: 5, Part: constants.Part1, Answer: "50", Outcome: submit.TooHigh},
+    {Day: 5, Part: constants.Part1, Answer: "7", Outcome: submit.TooLow},
+    {Day: 5, Part: constants.Part1, Answer: "30", Outcome: submit.Wrong},
+    {Day: 5, Part: constants.Part1, Answer: "31", Outcome: submit.Wait},
+    {Day: 5, Part: constants.Part1, Answer: "42", Outcome: submit.Correct},
+  } {
+    ledger.Record(attempt)
+  }
+  err = ledger.Save()
+  if err != nil { t.Fatal(err) }
+
+  ledger, err = submit.LoadLedger()
+  if err != nil { t.Fatal(err) }
+  if len(ledger.Attempts) != 5 { t.Fatalf("expected 5 attempts after reloading, got %d", len(ledger.Attempts)) }
+  accepted, isAccepted := ledger.Accepted(5, constants.Part1)
+  if !isAccepted || accepted != "42" { t.Errorf("expected 42 to be accepted, got %q", accepted) }
+  _, isAccepted = ledger.Accepted(5, constants.Part2)
+  if isAccepted { t.Error("part 2 was never submitted, yet it has an accepted answer") }
+
+  rejected := map[string]bool{"50": true, "60": true, "7": true, "3": true, "30": true, "31": false, "20": false, "abc": false}
+  for answer, isRejected := range rejected {
+    reason := ledger.Rejection(5, constants.Part1, answer)
+    if (reason != "") != isRejected { t.Errorf("answer %s: expected rejected to be %v, got reason %q", answer, isRejected, reason) }
+  }
+  if ledger.Rejection(5, constants.Part2, "50") != "" { t.Error("attempts of part 1 rejected an answer to part 2") }
+
+  inputs := ledger.FillExpected([]aocio.InputInfo{{Day: 5, Ver: 0}, {Day: 5, Ver: 1}})
+  if inputs[0].Part1 != "42" || inputs[0].Part2 != "" { t.Errorf("unexpected answers for the full input: %+v", inputs[0]) }
+  if inputs[1].Part1 != "" { t.Errorf("answers for the full input were used for an example: %+v", inputs[1]) }
+}
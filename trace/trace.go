@@ -0,0 +1,124 @@
+// Runtime tracing for the days. Each day declares its trace categories (e.g. d16's frontier or dijkstra) and guards
+// its trace output with them, so they can be turned on from the command line without recompiling:
+// -trace d16:frontier,dijkstra. Traces go to stderr by default, keeping them apart from the answers
+package trace
+
+import (
+	"aoc2k24/constants"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Enabling a day without naming categories only turns on those at Debug level. Verbose ones (renders, step by step
+// animations) have to be named, or enabled with :all
+type Level int
+
+const (
+  Debug Level = iota
+  Verbose
+)
+
+// Enables every category of the day when given in place of the category names
+const allCategories = "all"
+
+type Tracer struct {
+  Day constants.DayIndex
+  Name string
+  Level Level
+  Usage string
+  isOn bool
+}
+
+var (
+  tracers = []*Tracer{}
+  output io.Writer = os.Stderr
+  outputMutex sync.Mutex
+)
+
+// Declares a trace category of the day. Meant to be called when initializing the day's package variables
+func New(day constants.DayIndex, name string, level Level, usage string) *Tracer {
+  for _, tracer := range tracers {
+    if tracer.Day == day && tracer.Name == name {
+      panic(fmt.Sprintf("Trace category %s of day %d is declared more than once", name, day))
+    }
+  }
+  tracer := &Tracer{Day: day, Name: name, Level: level, Usage: usage}
+  tracers = append(tracers, tracer)
+  return tracer
+}
+
+// Whether the category is enabled. Checking it before building a trace message keeps disabled traces cheap
+func (t *Tracer) On() bool {
+  return t.isOn
+}
+
+func (t *Tracer) Printf(format string, args ...any) {
+  if !t.isOn { return }
+  outputMutex.Lock()
+  defer outputMutex.Unlock()
+  fmt.Fprintf(output, format, args...)
+}
+
+func (t *Tracer) Print(args ...any) {
+  if !t.isOn { return }
+  outputMutex.Lock()
+  defer outputMutex.Unlock()
+  fmt.Fprint(output, args...)
+}
+
+// Where traces are written, for code that writes to an io.Writer directly (e.g. the clear command of renders)
+func Output() io.Writer {
+  return output
+}
+
+func SetOutput(w io.Writer) {
+  output = w
+}
+
+// Every category declared by the day, in declaration order
+func Categories(day constants.DayIndex) []*Tracer {
+  categories := []*Tracer{}
+  for _, tracer := range tracers {
+    if tracer.Day == day { categories = append(categories, tracer) }
+  }
+  return categories
+}
+
+// Enables categories from a -trace value: d16 (every Debug category of the day), d16:frontier,dijkstra (just those)
+// or d16:all. The leading d is optional
+func Enable(spec string) error {
+  daySpec, names, hasNames := strings.Cut(spec, ":")
+  day, err := strconv.Atoi(strings.TrimPrefix(daySpec, "d"))
+  if err != nil { return fmt.Errorf("invalid trace %q, expected d<day>[:category,...]", spec) }
+  categories := Categories(constants.DayIndex(day))
+  if len(categories) == 0 { return fmt.Errorf("day %d has no trace categories", day) }
+  if !hasNames {
+    for _, tracer := range categories {
+      if tracer.Level == Debug { tracer.isOn = true }
+    }
+    return nil
+  }
+  for _, name := range strings.Split(names, ",") {
+    if name == allCategories {
+      for _, tracer := range categories { tracer.isOn = true }
+      continue
+    }
+    index := slices.IndexFunc(categories, func(t *Tracer) bool { return t.Name == name })
+    if index == -1 { return fmt.Errorf("day %d has no trace category %q, expected one of %s", day, name, categoryNames(categories)) }
+    categories[index].isOn = true
+  }
+  return nil
+}
+
+func categoryNames(categories []*Tracer) string {
+  names := make([]string, len(categories))
+  for i, tracer := range categories {
+    names[i] = tracer.Name
+  }
+  return strings.Join(names, ", ")
+}